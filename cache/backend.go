@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+// Entry is a cached price together with the product attributes it was
+// computed from, so a product whose price or category changed is a miss.
+type Entry struct {
+	Category catalog.Category
+	Price    catalog.Price
+	Result   catalog.DiscountedPrice
+}
+
+// Backend stores the cached prices by key. Get returns nil on a miss.
+type Backend interface {
+	Get(ctx context.Context, key string) (*Entry, error)
+	Set(ctx context.Context, key string, e Entry, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Purge(ctx context.Context) error
+}
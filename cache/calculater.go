@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/logging"
+	"go.uber.org/zap"
+)
+
+// Calculater is a read-through cache of the discounted prices computed by the
+// decorated Calculater. Cache failures are logged and never fail pricing.
+type Calculater struct {
+	next    catalog.Calculater
+	backend Backend
+	ttl     time.Duration
+	// generation changes on every invalidation, so prices computed while
+	// the catalog was being written are not cached.
+	generation uint64
+}
+
+func NewCalculater(next catalog.Calculater, b Backend, ttl time.Duration) *Calculater {
+	return &Calculater{next: next, backend: b, ttl: ttl}
+}
+
+func (c *Calculater) Calculate(ctx context.Context, p catalog.Product) (*catalog.DiscountedPrice, error) {
+	// only the prices for everyone are cached, explained prices or prices for
+	// a pricing context or with a promo code are neither served from nor
+	// written to the cache.
+	if catalog.ExplanationRequested(ctx) || !catalog.PricingContextFrom(ctx).IsZero() || catalog.PromoCodeFrom(ctx) != "" {
+		return c.next.Calculate(ctx, p)
+	}
+	key := string(p.SKU)
+	e, err := c.backend.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("could not read cached price", zap.String("sku", key), zap.Error(err))
+	}
+	if e != nil && e.Category == p.Category && e.Price == p.Price {
+		price := e.Result
+		return &price, nil
+	}
+
+	generation := atomic.LoadUint64(&c.generation)
+	price, err := c.next.Calculate(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if atomic.LoadUint64(&c.generation) != generation {
+		return price, nil
+	}
+	if err := c.backend.Set(ctx, key, Entry{p.Category, p.Price, *price}, c.ttl); err != nil {
+		logging.FromContext(ctx).Warn("could not cache price", zap.String("sku", key), zap.Error(err))
+	}
+	return price, nil
+}
+
+// Invalidate drops the cached prices of the given products.
+func (c *Calculater) Invalidate(ctx context.Context, skus ...catalog.SKU) error {
+	atomic.AddUint64(&c.generation, 1)
+	keys := make([]string, 0, len(skus))
+	for _, sku := range skus {
+		keys = append(keys, string(sku))
+	}
+	return c.backend.Delete(ctx, keys...)
+}
+
+// InvalidateAll drops every cached price.
+func (c *Calculater) InvalidateAll(ctx context.Context) error {
+	atomic.AddUint64(&c.generation, 1)
+	return c.backend.Purge(ctx)
+}
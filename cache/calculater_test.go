@@ -0,0 +1,83 @@
+package cache_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/cache"
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingCalculater struct {
+	catalog.Calculater
+	calls int32
+}
+
+func (c *countingCalculater) Calculate(ctx context.Context, p catalog.Product) (*catalog.DiscountedPrice, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return c.Calculater.Calculate(ctx, p)
+}
+
+func TestCalculater_Calculate(t *testing.T) {
+	ctx := context.Background()
+	boots := catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)
+	hat := catalog.NewProduct("000006", "AA hat", "hats", 72000)
+
+	productRepo := inmem.NewProductRepo([]*catalog.Product{boots, hat})
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	next := &countingCalculater{Calculater: pricingacl.NewCalculater(discountRepo)}
+	c := cache.NewCalculater(next, cache.NewLRU(10), time.Minute)
+	products, discounts := c.ProductStore(productRepo), c.DiscountStore(discountRepo)
+
+	price, err := c.Calculate(ctx, *boots)
+	assert.NoError(t, err)
+	assert.Equal(t, catalog.Price(62300), price.Final)
+
+	price, _ = c.Calculate(ctx, *boots)
+	assert.Equal(t, catalog.Price(62300), price.Final)
+	assert.Equal(t, int32(1), next.calls, "the second price is cached")
+
+	repriced := catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 10000)
+	price, _ = c.Calculate(ctx, *repriced)
+	assert.Equal(t, catalog.Price(7000), price.Final, "a changed product price is a miss")
+	assert.Equal(t, int32(2), next.calls)
+
+	_ = discounts.Save([]catalog.Discount{catalog.NewProductDiscount("000001", 50)})
+	price, _ = c.Calculate(ctx, *repriced)
+	assert.Equal(t, catalog.Price(5000), price.Final, "a product discount invalidates its product")
+	assert.Equal(t, int32(3), next.calls)
+
+	_, _ = c.Calculate(ctx, *hat)
+	_ = discounts.Save([]catalog.Discount{catalog.NewCategoryDiscount("hats", 10)})
+	price, _ = c.Calculate(ctx, *hat)
+	assert.Equal(t, catalog.Price(64800), price.Final, "a category discount invalidates every price")
+	assert.Equal(t, int32(5), next.calls)
+
+	_ = products.Save([]*catalog.Product{hat})
+	_, _ = c.Calculate(ctx, *hat)
+	assert.Equal(t, int32(6), next.calls, "a saved product is invalidated")
+
+	_, _ = c.Calculate(ctx, *hat)
+	assert.Equal(t, int32(6), next.calls)
+	_ = c.InvalidateAll(ctx)
+	_, _ = c.Calculate(ctx, *hat)
+	assert.Equal(t, int32(7), next.calls)
+
+	price, _ = c.Calculate(catalog.WithExplanation(ctx), *hat)
+	assert.NotNil(t, price.Explanation)
+	assert.Equal(t, int32(8), next.calls, "an explained price is never cached")
+	price, _ = c.Calculate(ctx, *hat)
+	assert.Nil(t, price.Explanation)
+	assert.Equal(t, int32(8), next.calls)
+
+	_, _ = c.Calculate(catalog.WithPricingContext(ctx, catalog.PricingContext{Segment: "b2b"}), *hat)
+	assert.Equal(t, int32(9), next.calls, "a price for a pricing context is never cached")
+
+	_, _ = c.Calculate(catalog.WithPromoCode(ctx, "SPRING10"), *hat)
+	assert.Equal(t, int32(10), next.calls, "a price with a promo code is never cached")
+}
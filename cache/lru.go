@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Backend holding at most size entries, evicting the
+// least recently used one when full.
+type LRU struct {
+	size    int
+	mu      sync.Mutex
+	items   map[string]*list.Element
+	entries *list.List
+	now     func() time.Time
+}
+
+type lruItem struct {
+	key       string
+	entry     Entry
+	expiresAt time.Time
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{size: size, items: make(map[string]*list.Element), entries: list.New(), now: time.Now}
+}
+
+func (c *LRU) Get(ctx context.Context, key string) (*Entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+	item := el.Value.(*lruItem)
+	if !item.expiresAt.IsZero() && !c.now().Before(item.expiresAt) {
+		c.remove(el)
+		return nil, nil
+	}
+	c.entries.MoveToFront(el)
+	e := item.entry
+	return &e, nil
+}
+
+func (c *LRU) Set(ctx context.Context, key string, e Entry, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		el.Value = &lruItem{key, e, expiresAt}
+		c.entries.MoveToFront(el)
+		return nil
+	}
+	c.items[key] = c.entries.PushFront(&lruItem{key, e, expiresAt})
+	for c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+	}
+	return nil
+}
+
+func (c *LRU) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+func (c *LRU) Purge(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.entries.Init()
+	return nil
+}
+
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.entries.Remove(el)
+	delete(c.items, el.Value.(*lruItem).key)
+}
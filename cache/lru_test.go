@@ -0,0 +1,65 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/cache"
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func entry(price catalog.Price) cache.Entry {
+	return cache.Entry{Category: "boots", Price: price, Result: *catalog.NewDiscountedPrice(price, nil)}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	lru := cache.NewLRU(2)
+
+	_ = lru.Set(ctx, "000001", entry(100), 0)
+	_ = lru.Set(ctx, "000002", entry(200), 0)
+	_, _ = lru.Get(ctx, "000001")
+	_ = lru.Set(ctx, "000003", entry(300), 0)
+
+	got, _ := lru.Get(ctx, "000002")
+	assert.Nil(t, got)
+	got, _ = lru.Get(ctx, "000001")
+	assert.Equal(t, catalog.Price(100), got.Price)
+	got, _ = lru.Get(ctx, "000003")
+	assert.Equal(t, catalog.Price(300), got.Price)
+	assert.Equal(t, 2, lru.Len())
+}
+
+func TestLRU_Expires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	lru := cache.NewLRU(10)
+	lru.SetClock(func() time.Time { return now })
+
+	_ = lru.Set(ctx, "000001", entry(100), time.Minute)
+
+	now = now.Add(59 * time.Second)
+	got, _ := lru.Get(ctx, "000001")
+	assert.NotNil(t, got)
+
+	now = now.Add(time.Second)
+	got, _ = lru.Get(ctx, "000001")
+	assert.Nil(t, got)
+	assert.Equal(t, 0, lru.Len())
+}
+
+func TestLRU_DeleteAndPurge(t *testing.T) {
+	ctx := context.Background()
+	lru := cache.NewLRU(10)
+	_ = lru.Set(ctx, "000001", entry(100), 0)
+	_ = lru.Set(ctx, "000002", entry(200), 0)
+	_ = lru.Set(ctx, "000003", entry(300), 0)
+
+	_ = lru.Delete(ctx, "000001", "000004")
+	assert.Equal(t, 2, lru.Len())
+
+	_ = lru.Purge(ctx)
+	assert.Equal(t, 0, lru.Len())
+}
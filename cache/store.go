@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+type productStore struct {
+	next  catalog.ProductStore
+	cache *Calculater
+}
+
+// ProductStore invalidates the cached prices of the products written to next.
+func (c *Calculater) ProductStore(next catalog.ProductStore) catalog.ProductStore {
+	return &productStore{next, c}
+}
+
+func (s *productStore) Save(products []*catalog.Product) error {
+	if err := s.next.Save(products); err != nil {
+		return err
+	}
+	skus := make([]catalog.SKU, 0, len(products))
+	for _, p := range products {
+		skus = append(skus, p.SKU)
+	}
+	return s.cache.Invalidate(context.Background(), skus...)
+}
+
+func (s *productStore) ReplaceAll(products []*catalog.Product) error {
+	if err := s.next.ReplaceAll(products); err != nil {
+		return err
+	}
+	return s.cache.InvalidateAll(context.Background())
+}
+
+type discountStore struct {
+	next  catalog.DiscountStore
+	cache *Calculater
+}
+
+// DiscountStore invalidates the cached prices affected by the discounts
+// written to next: the product of a product discount, or every price when a
+// category discount changes.
+func (c *Calculater) DiscountStore(next catalog.DiscountStore) catalog.DiscountStore {
+	return &discountStore{next, c}
+}
+
+func (s *discountStore) Save(discounts []catalog.Discount) error {
+	if err := s.next.Save(discounts); err != nil {
+		return err
+	}
+	var skus []catalog.SKU
+	for _, d := range discounts {
+		pd, ok := d.(*catalog.ProductDiscount)
+		if !ok {
+			return s.cache.InvalidateAll(context.Background())
+		}
+		skus = append(skus, pd.SKU())
+	}
+	return s.cache.Invalidate(context.Background(), skus...)
+}
+
+func (s *discountStore) ReplaceAll(discounts []catalog.Discount) error {
+	if err := s.next.ReplaceAll(discounts); err != nil {
+		return err
+	}
+	return s.cache.InvalidateAll(context.Background())
+}
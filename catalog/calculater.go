@@ -0,0 +1,83 @@
+package catalog
+
+import "context"
+
+// Calculater prices the catalog products. It is the port of the catalog to
+// the pricing context.
+type Calculater interface {
+	Calculate(ctx context.Context, p Product) (*DiscountedPrice, error)
+}
+
+// PriceExplanation lists the discounts matching a priced product, telling
+// which one was applied and why the others were not.
+type PriceExplanation struct {
+	Candidates []DiscountCandidate `json:"candidates"`
+}
+
+// CouponSource is the source of the candidates of a promo code.
+const CouponSource = "coupon"
+
+type DiscountCandidate struct {
+	// Source is the discount type, product or category, or coupon.
+	Source     string             `json:"source"`
+	Target     string             `json:"target"`
+	Percentage DiscountPercentage `json:"percentage"`
+	// Audience is only set for the discounts limited to a pricing context.
+	Audience *PricingContext `json:"audience,omitempty"`
+	Applied  bool            `json:"applied"`
+	Reason   string          `json:"reason"`
+}
+
+type explainKey struct{}
+
+// WithExplanation asks the calculaters to explain the prices calculated with
+// the returned context.
+func WithExplanation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, explainKey{}, true)
+}
+
+func ExplanationRequested(ctx context.Context) bool {
+	explain, _ := ctx.Value(explainKey{}).(bool)
+	return explain
+}
+
+// PricingContext is who a product is priced for: a customer segment, a sales
+// channel and a country. Discounts limited to a pricing context only apply
+// to the prices calculated for it, see WithPricingContext.
+type PricingContext struct {
+	Segment string `json:"segment,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+func (pc PricingContext) IsZero() bool {
+	return pc == PricingContext{}
+}
+
+type pricingContextKey struct{}
+
+// WithPricingContext prices the products calculated with the returned context
+// for pc.
+func WithPricingContext(ctx context.Context, pc PricingContext) context.Context {
+	return context.WithValue(ctx, pricingContextKey{}, pc)
+}
+
+// PricingContextFrom returns the pricing context of ctx, zero when prices are
+// calculated for everyone.
+func PricingContextFrom(ctx context.Context) PricingContext {
+	pc, _ := ctx.Value(pricingContextKey{}).(PricingContext)
+	return pc
+}
+
+type promoCodeKey struct{}
+
+// WithPromoCode prices the products calculated with the returned context with
+// the promo code, where it applies.
+func WithPromoCode(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, promoCodeKey{}, code)
+}
+
+func PromoCodeFrom(ctx context.Context) string {
+	code, _ := ctx.Value(promoCodeKey{}).(string)
+	return code
+}
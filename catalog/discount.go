@@ -1,5 +1,11 @@
 package catalog
 
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/money"
+)
+
 const EURCurrency = Currency("EUR")
 
 type DiscountPercentage int
@@ -8,44 +14,79 @@ type Currency string
 
 type Discount interface {
 	Percentage() DiscountPercentage
+	// Audience is the pricing context the discount is limited to, its empty
+	// fields matching any.
+	Audience() PricingContext
+}
+
+type DiscountOption func(audience *PricingContext)
+
+// ForAudience limits a discount to the prices calculated for pc.
+func ForAudience(pc PricingContext) DiscountOption {
+	return func(audience *PricingContext) {
+		*audience = pc
+	}
+}
+
+func newAudience(opts []DiscountOption) PricingContext {
+	var audience PricingContext
+	for _, opt := range opts {
+		opt(&audience)
+	}
+	return audience
 }
 
 type DiscountRepository interface {
-	Find(search SearchCriteria) (discounts []Discount, err error)
+	Find(ctx context.Context, search SearchCriteria) (discounts []Discount, err error)
+}
+
+type DiscountStore interface {
+	Save(discounts []Discount) error
+	ReplaceAll(discounts []Discount) error
 }
 
 type ProductDiscount struct {
 	sku        SKU
 	percentage DiscountPercentage
+	audience   PricingContext
 }
 
 func (d *ProductDiscount) Percentage() DiscountPercentage {
 	return d.percentage
 }
 
+func (d *ProductDiscount) Audience() PricingContext {
+	return d.audience
+}
+
 func (d *ProductDiscount) SKU() SKU {
 	return d.sku
 }
 
-func NewProductDiscount(sku SKU, dp DiscountPercentage) Discount {
-	return &ProductDiscount{sku, dp}
+func NewProductDiscount(sku SKU, dp DiscountPercentage, opts ...DiscountOption) Discount {
+	return &ProductDiscount{sku, dp, newAudience(opts)}
 }
 
 type CategoryDiscount struct {
 	category   Category
 	percentage DiscountPercentage
+	audience   PricingContext
 }
 
 func (d *CategoryDiscount) Percentage() DiscountPercentage {
 	return d.percentage
 }
 
+func (d *CategoryDiscount) Audience() PricingContext {
+	return d.audience
+}
+
 func (d *CategoryDiscount) Category() Category {
 	return d.category
 }
 
-func NewCategoryDiscount(cat Category, dp DiscountPercentage) Discount {
-	return &CategoryDiscount{cat, dp}
+func NewCategoryDiscount(cat Category, dp DiscountPercentage, opts ...DiscountOption) Discount {
+	return &CategoryDiscount{cat, dp, newAudience(opts)}
 }
 
 type DiscountedPrice struct {
@@ -53,14 +94,28 @@ type DiscountedPrice struct {
 	Final              Price               `json:"final"`
 	DiscountPercentage *DiscountPercentage `json:"discount_percentage"`
 	Currenty           Currency            `json:"currency"`
+	// Lowest30DaysPrice is the lowest price of the last 30 days, only set
+	// when a discount is applied.
+	Lowest30DaysPrice *Price `json:"lowest_30_days_price,omitempty"`
+	// PromoCode is only set when the discount of a promo code is applied.
+	PromoCode string `json:"promo_code,omitempty"`
+	// Explanation is only set when requested, see WithExplanation.
+	Explanation *PriceExplanation `json:"explanation,omitempty"`
 }
 
+// NewDiscountedPrice rounds the discounted price half up, a negative price or
+// a percentage out of 0-100 leaving the original price without discount.
 func NewDiscountedPrice(original Price, dp *DiscountPercentage) *DiscountedPrice {
 	final := original
 	if dp != nil {
-		final = Price(uint(original) - (uint(original) * uint(*dp) / 100))
+		discounted, err := money.Amount(original).Discount(int(*dp), money.HalfUp)
+		if err != nil {
+			dp = nil
+		} else {
+			final = Price(discounted)
+		}
 	}
-	return &DiscountedPrice{original, final, dp, EURCurrency}
+	return &DiscountedPrice{Original: original, Final: final, DiscountPercentage: dp, Currenty: EURCurrency}
 }
 
 type DiscountedProduct struct {
@@ -0,0 +1,38 @@
+package catalog_test
+
+import (
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func percentage(p catalog.DiscountPercentage) *catalog.DiscountPercentage {
+	return &p
+}
+
+func TestNewDiscountedPrice(t *testing.T) {
+	tests := map[string]struct {
+		original catalog.Price
+		dp       *catalog.DiscountPercentage
+		want     catalog.Price
+		wantDP   *catalog.DiscountPercentage
+	}{
+		"Without discount":         {original: 89000, want: 89000},
+		"With discount":            {original: 89000, dp: percentage(30), want: 62300, wantDP: percentage(30)},
+		"Rounded half up":          {original: 999, dp: percentage(15), want: 849, wantDP: percentage(15)},
+		"Whole discount":           {original: 89000, dp: percentage(100), want: 0, wantDP: percentage(100)},
+		"Negative price":           {original: -100, dp: percentage(30), want: -100},
+		"Percentage out of bounds": {original: 89000, dp: percentage(150), want: 89000},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := catalog.NewDiscountedPrice(tc.original, tc.dp)
+
+			assert.Equal(t, tc.want, got.Final)
+			assert.Equal(t, tc.original, got.Original)
+			assert.Equal(t, tc.wantDP, got.DiscountPercentage, "a discount that is not applied is not reported")
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ErrorCode string
+
+const (
+	InternalError        = ErrorCode("internal")
+	NotFoundError        = ErrorCode("not_found")
+	InvalidArgumentError = ErrorCode("invalid_argument")
+	UnavailableError     = ErrorCode("unavailable")
+	ConflictError        = ErrorCode("conflict")
+	UnauthenticatedError = ErrorCode("unauthenticated")
+)
+
+var (
+	ErrNotFound        = &Error{Code: NotFoundError, Message: "not found"}
+	ErrInvalidArgument = &Error{Code: InvalidArgumentError, Message: "invalid argument"}
+	ErrUnavailable     = &Error{Code: UnavailableError, Message: "unavailable"}
+	ErrConflict        = &Error{Code: ConflictError, Message: "conflict"}
+	ErrUnauthenticated = &Error{Code: UnauthenticatedError, Message: "unauthenticated"}
+)
+
+// Error is a catalog error classified by code, so callers can tell a bad
+// request apart from a failing dependency. errors.Is matches any Error against
+// ErrNotFound, ErrInvalidArgument, ErrUnavailable, ErrConflict and
+// ErrUnauthenticated by code.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+func NewError(code ErrorCode, err error, format string, args ...interface{}) *Error {
+	return &Error{code, fmt.Sprintf(format, args...), err}
+}
+
+func NewNotFoundError(format string, args ...interface{}) *Error {
+	return NewError(NotFoundError, nil, format, args...)
+}
+
+func NewInvalidArgumentError(format string, args ...interface{}) *Error {
+	return NewError(InvalidArgumentError, nil, format, args...)
+}
+
+func NewUnavailableError(err error, format string, args ...interface{}) *Error {
+	return NewError(UnavailableError, err, format, args...)
+}
+
+func NewConflictError(format string, args ...interface{}) *Error {
+	return NewError(ConflictError, nil, format, args...)
+}
+
+func NewUnauthenticatedError(format string, args ...interface{}) *Error {
+	return NewError(UnauthenticatedError, nil, format, args...)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound, ErrInvalidArgument, ErrUnavailable, ErrConflict, ErrUnauthenticated:
+		return target.(*Error).Code == e.Code
+	}
+	return false
+}
+
+// ErrorCodeOf returns the code of the first catalog Error in the chain of err,
+// unclassified errors are internal.
+func ErrorCodeOf(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return InternalError
+}
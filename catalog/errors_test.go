@@ -0,0 +1,19 @@
+package catalog_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	wrapped := fmt.Errorf("adapter: %w", catalog.NewNotFoundError("sku %q not found", "000042"))
+
+	assert.Equal(t, catalog.NotFoundError, catalog.ErrorCodeOf(wrapped))
+	assert.ErrorIs(t, wrapped, catalog.ErrNotFound)
+	assert.NotErrorIs(t, wrapped, catalog.ErrUnavailable)
+	assert.Equal(t, catalog.InternalError, catalog.ErrorCodeOf(errors.New("boom")))
+}
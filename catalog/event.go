@@ -0,0 +1,65 @@
+package catalog
+
+import "time"
+
+type EventType string
+
+const (
+	ProductCreatedEvent  = EventType("product.created")
+	PriceChangedEvent    = EventType("product.price_changed")
+	DiscountAppliedEvent = EventType("discount.applied")
+	DiscountExpiredEvent = EventType("discount.expired")
+
+	ProductDiscountType  = "product"
+	CategoryDiscountType = "category"
+)
+
+// Event is a change of the catalog emitted by the write side. ID and
+// OccurredAt are set by the outbox storing it.
+type Event struct {
+	ID            uint64              `json:"id"`
+	Type          EventType           `json:"type"`
+	OccurredAt    time.Time           `json:"occurred_at"`
+	SKU           SKU                 `json:"sku,omitempty"`
+	Category      Category            `json:"category,omitempty"`
+	Price         *Price              `json:"price,omitempty"`
+	PreviousPrice *Price              `json:"previous_price,omitempty"`
+	DiscountType  string              `json:"discount_type,omitempty"`
+	Percentage    *DiscountPercentage `json:"percentage,omitempty"`
+	Audience      *PricingContext     `json:"audience,omitempty"`
+}
+
+func NewProductCreatedEvent(p *Product) Event {
+	price := p.Price
+	return Event{Type: ProductCreatedEvent, SKU: p.SKU, Category: p.Category, Price: &price}
+}
+
+func NewPriceChangedEvent(p *Product, previous Price) Event {
+	price := p.Price
+	return Event{Type: PriceChangedEvent, SKU: p.SKU, Category: p.Category, Price: &price, PreviousPrice: &previous}
+}
+
+func NewDiscountAppliedEvent(d Discount) Event {
+	e := discountEvent(DiscountAppliedEvent, d)
+	percentage := d.Percentage()
+	e.Percentage = &percentage
+	return e
+}
+
+func NewDiscountExpiredEvent(d Discount) Event {
+	return discountEvent(DiscountExpiredEvent, d)
+}
+
+func discountEvent(t EventType, d Discount) Event {
+	e := Event{Type: t}
+	switch discount := d.(type) {
+	case *ProductDiscount:
+		e.DiscountType, e.SKU = ProductDiscountType, discount.SKU()
+	case *CategoryDiscount:
+		e.DiscountType, e.Category = CategoryDiscountType, discount.Category()
+	}
+	if audience := d.Audience(); !audience.IsZero() {
+		e.Audience = &audience
+	}
+	return e
+}
@@ -14,7 +14,7 @@ func NewPagination(limit, offset int) (*Pagination, error) {
 	if err := assertLimit(limit); err != nil {
 		return nil, err
 	}
-	if err := assertOffset(limit); err != nil {
+	if err := assertOffset(offset); err != nil {
 		return nil, err
 	}
 
@@ -22,11 +22,15 @@ func NewPagination(limit, offset int) (*Pagination, error) {
 }
 
 func assertLimit(limit int) error {
-	//WIP
+	if limit < 1 {
+		return NewInvalidArgumentError("limit must be greater than 0, got %d", limit)
+	}
 	return nil
 }
 
 func assertOffset(offset int) error {
-	//WIP
+	if offset < 0 {
+		return NewInvalidArgumentError("offset must not be negative, got %d", offset)
+	}
 	return nil
 }
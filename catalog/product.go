@@ -1,11 +1,18 @@
 package catalog
 
+import "context"
+
 type SKU string
 type Price int
 type Category string
 
 type ProductRepository interface {
-	List(search SearchCriteria) (products *PaginatedProducts, err error)
+	List(ctx context.Context, search SearchCriteria) (products *PaginatedProducts, err error)
+}
+
+type ProductStore interface {
+	Save(products []*Product) error
+	ReplaceAll(products []*Product) error
 }
 
 type Product struct {
@@ -0,0 +1,6 @@
+package catalog
+
+// Versioner reports the version of stored data, which changes on every write.
+type Versioner interface {
+	Version() uint64
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/amelendres/go-catalog/cache"
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/config"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/events"
+	"github.com/amelendres/go-catalog/health"
+	"github.com/amelendres/go-catalog/history"
+	"github.com/amelendres/go-catalog/importing"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/logging"
+	"github.com/amelendres/go-catalog/metrics"
+	"github.com/amelendres/go-catalog/money"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/simulation"
+	"github.com/amelendres/go-catalog/storage/file"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/streaming"
+	"github.com/amelendres/go-catalog/tracing"
+	"github.com/amelendres/go-catalog/webhooks"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type productStorage interface {
+	catalog.ProductRepository
+	catalog.ProductStore
+}
+
+type discountStorage interface {
+	catalog.DiscountRepository
+	catalog.DiscountStore
+}
+
+type app struct {
+	config        *config.Config
+	productRepo   productStorage
+	discountRepo  discountStorage
+	productStore  catalog.ProductStore
+	discountStore catalog.DiscountStore
+	products      catalog.ProductRepository
+	discounts     catalog.DiscountRepository
+	productLister listing.ProductLister
+	calculater    catalog.Calculater
+	pricing       pricing.Engine
+	simulator     *simulation.Simulator
+	priceCache    *cache.Calculater
+	history       *history.Service
+	outbox        events.Outbox
+	dispatcher    *events.Dispatcher
+	webhooks      *webhooks.Service
+	webhookRepo   webhooks.Repository
+	coupons       *coupons.Service
+	stream        *streaming.Broker
+	metrics       *metrics.Registry
+	logger        *zap.Logger
+	tracer        *tracing.Tracer
+	readiness     *health.Readiness
+	closers       []io.Closer
+}
+
+func newApp(cfg *config.Config) (*app, error) {
+	a := &app{
+		config:    cfg,
+		metrics:   metrics.NewRegistry(),
+		logger:    logging.New(os.Stderr, zap.InfoLevel),
+		readiness: health.NewReadiness(),
+	}
+
+	switch cfg.Storage.Backend {
+	case config.InmemBackend:
+		var opts []inmem.Option
+		// the inmem catalog is only written by this process, which records
+		// its events when it dispatches them.
+		if cfg.Events.Publisher != config.NoPublisher || cfg.Webhooks.Enabled || cfg.Stream.Enabled {
+			o := inmem.NewOutbox()
+			opts, a.outbox = append(opts, inmem.WithOutbox(o)), o
+		}
+		products, discounts := sampleCatalog(cfg.Seed)
+		a.productRepo = inmem.NewProductRepo(products, opts...)
+		a.discountRepo = inmem.NewDiscountRepo(discounts, opts...)
+		a.history = history.NewService(inmem.NewHistoryRepo())
+		a.productStore, a.discountStore = a.history.ProductStore(a.productRepo), a.history.DiscountStore(a.discountRepo)
+		a.webhookRepo = inmem.NewWebhookRepo()
+		a.coupons = coupons.NewService(inmem.NewCouponRepo())
+	case config.FileBackend:
+		// the events are always recorded, as the server dispatching them may
+		// not be the process writing the catalog.
+		store, err := file.Open(cfg.Storage.DSN, file.OnReload(a.invalidatePrices), file.WithOutbox())
+		if err != nil {
+			return nil, err
+		}
+		a.productRepo = store.Products()
+		a.discountRepo = store.Discounts()
+		a.history = history.NewService(store.History())
+		// the store records the history in the same snapshot as the write.
+		a.productStore, a.discountStore = a.productRepo, a.discountRepo
+		a.webhookRepo = store.Webhooks()
+		a.coupons = coupons.NewService(store.Coupons())
+		a.closers = append(a.closers, store)
+		a.outbox = store.Outbox()
+	}
+
+	if err := a.seed(); err != nil {
+		return nil, err
+	}
+
+	tp, err := tracing.NewProvider(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	a.tracer = tracing.New(tp)
+	if cfg.Tracing.Exporter != tracing.NoneExporter {
+		a.closers = append(a.closers, closerFunc(func() error {
+			return tp.Shutdown(context.Background())
+		}))
+	}
+
+	strategy, err := pricing.ParseStrategy(cfg.Pricing.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	rounding, err := money.ParseRounding(cfg.Pricing.Rounding)
+	if err != nil {
+		return nil, err
+	}
+	a.products = a.tracer.ProductRepository(a.metrics.ProductRepository(a.productRepo))
+	a.discounts = a.tracer.DiscountRepository(a.metrics.DiscountRepository(a.discountRepo))
+	a.pricing = pricing.NewEngine(
+		pricingacl.NewCouponRules(pricingacl.NewRules(a.discounts), a.coupons),
+		pricing.WithStrategy(strategy),
+		pricing.WithRounding(rounding),
+		pricing.WithObserver(a.metrics),
+	)
+	pricingCalculater := pricingacl.NewLocal(a.pricing)
+	if cfg.Pricing.Endpoint != "" {
+		conn, err := grpc.Dial(cfg.Pricing.Endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("could not dial pricing service %s %w", cfg.Pricing.Endpoint, err)
+		}
+		a.closers = append(a.closers, conn)
+		pricingCalculater = pricingacl.NewRemote(conn)
+	}
+	if cfg.PriceCache.Size > 0 {
+		a.priceCache = cache.NewCalculater(pricingCalculater, cache.NewLRU(cfg.PriceCache.Size), cfg.PriceCache.TTL)
+		pricingCalculater = a.priceCache
+	}
+	a.calculater = a.tracer.Calculater(a.history.Calculater(pricingCalculater))
+	productLister := listing.NewProductLister(a.products, a.calculater)
+	// simulations price in-process, as proposed discounts are only known here.
+	a.simulator = simulation.NewSimulator(a.products, a.discounts, func(r catalog.DiscountRepository) catalog.Calculater {
+		rules := pricingacl.NewCouponRules(pricingacl.NewRules(r), a.coupons)
+		return pricingacl.NewLocal(pricing.NewEngine(rules, pricing.WithStrategy(strategy), pricing.WithRounding(rounding)))
+	}, simulation.WithLowestPrices(a.history))
+	a.productLister = a.tracer.ProductLister(productLister)
+
+	return a, nil
+}
+
+// dispatchEvents builds the publishers of the recorded catalog events and
+// their dispatcher. Only the server dispatches, the other commands leaving
+// the events they record to it.
+func (a *app) dispatchEvents() error {
+	if a.outbox == nil {
+		return nil
+	}
+	publisher, err := a.publisher()
+	if err != nil {
+		return err
+	}
+	a.dispatcher = events.NewDispatcher(a.outbox, publisher, events.WithInterval(a.config.Events.Interval), events.WithLogger(a.logger))
+	return nil
+}
+
+// invalidatePrices drops the cached prices once the catalog written by
+// another process is reloaded.
+func (a *app) invalidatePrices() {
+	if a.priceCache != nil {
+		_ = a.priceCache.InvalidateAll(context.Background())
+	}
+}
+
+func (a *app) healthChecker() *health.Checker {
+	checker := health.NewChecker(a.readiness)
+	if p, ok := a.productRepo.(health.Pinger); ok {
+		checker.Register("products", p)
+	}
+	if p, ok := a.discountRepo.(health.Pinger); ok {
+		checker.Register("discounts", p)
+	}
+	return checker
+}
+
+func (a *app) publisher() (events.Publisher, error) {
+	var publishers events.Publishers
+	if a.config.Events.Publisher == config.FilePublisher {
+		f, err := os.OpenFile(a.config.Events.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("could not open events file %s %w", a.config.Events.Path, err)
+		}
+		a.closers = append(a.closers, f)
+		publishers = append(publishers, events.NewWriterPublisher(f))
+	}
+	if a.config.Webhooks.Enabled {
+		opts := []webhooks.Option{
+			webhooks.WithRetries(a.config.Webhooks.MaxAttempts, a.config.Webhooks.Backoff),
+			webhooks.WithLogger(a.logger),
+		}
+		if a.config.Webhooks.AllowPrivate {
+			opts = append(opts, webhooks.WithPrivateNetworks())
+		}
+		a.webhooks = webhooks.NewService(a.webhookRepo, a.productLister, opts...)
+		if err := a.webhooks.Prime(context.Background()); err != nil {
+			return nil, fmt.Errorf("could not price the catalog for webhooks %w", err)
+		}
+		a.closers = append(a.closers, a.webhooks)
+		publishers = append(publishers, a.webhooks)
+	}
+	if a.config.Stream.Enabled {
+		a.stream = streaming.NewBroker(a.productLister)
+		publishers = append(publishers, a.stream)
+	}
+	return publishers, nil
+}
+
+// versions returns the data versions the REST ETags are derived from.
+func (a *app) versions() []catalog.Versioner {
+	var versions []catalog.Versioner
+	if v, ok := a.productRepo.(catalog.Versioner); ok {
+		versions = append(versions, v)
+	}
+	if v, ok := a.discountRepo.(catalog.Versioner); ok {
+		versions = append(versions, v)
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	// the lowest prices of the last 30 days change as the window slides.
+	return append(versions, a.history)
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func (a *app) close() error {
+	var err error
+	for _, c := range a.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (a *app) importer() importing.Importer {
+	products, discounts := a.productStore, a.discountStore
+	if a.priceCache != nil {
+		products, discounts = a.priceCache.ProductStore(products), a.priceCache.DiscountStore(discounts)
+	}
+	return importing.NewImporter(products, discounts)
+}
+
+func (a *app) seed() error {
+	seeds := []struct {
+		kind string
+		path string
+	}{
+		{productsKind, a.config.Seed.Products},
+		{discountsKind, a.config.Seed.Discounts},
+		{couponsKind, a.config.Seed.Coupons},
+	}
+	for _, s := range seeds {
+		if s.path == "" {
+			continue
+		}
+		if _, err := a.importFile(s.kind, s.path, "", importing.UpsertMode); err != nil {
+			return fmt.Errorf("could not seed %s from %s %w", s.kind, s.path, err)
+		}
+	}
+	return nil
+}
+
+func (a *app) importFile(kind, path, format string, m importing.Mode) (*importing.Report, error) {
+	f, err := importing.FormatFromPath(path)
+	if format != "" {
+		f, err = importing.ParseFormat(format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch kind {
+	case productsKind:
+		return a.importer().ImportProducts(file, f, m)
+	case discountsKind:
+		return a.importer().ImportDiscounts(file, f, m)
+	case couponsKind:
+		// coupons are only upserted from JSON, replacing them would reset
+		// the uses of the ones kept.
+		if f != importing.JSONFormat || m != importing.UpsertMode {
+			return nil, fmt.Errorf("coupons are only imported from json in %s mode", importing.UpsertMode)
+		}
+		n, err := a.coupons.Import(context.Background(), file)
+		if err != nil {
+			return nil, err
+		}
+		return &importing.Report{Imported: n}, nil
+	}
+	return nil, fmt.Errorf("unknown kind %q", kind)
+}
+
+// sampleCatalog returns the demo catalog served by the inmem backend when
+// no seed files are configured.
+func sampleCatalog(seed config.Seed) ([]*catalog.Product, []catalog.Discount) {
+	var products []*catalog.Product
+	if seed.Products == "" {
+		products = newProductsFromJSON(givenProductsJSON)
+	}
+	var discounts []catalog.Discount
+	if seed.Discounts == "" {
+		discounts = givenDiscounts
+	}
+	return products, discounts
+}
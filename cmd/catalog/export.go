@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/amelendres/go-catalog/config"
+	"github.com/amelendres/go-catalog/exporting"
+)
+
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	loader := config.NewLoader(fs)
+	format := fs.String("format", string(exporting.CSVFormat), "feed format: csv, jsonl, merchant-xml or merchant-tsv")
+	output := fs.String("o", "", "output file (default: stdout)")
+	linkBase := fs.String("link", "", "storefront base URL used to build product links")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: catalog export [-format csv|jsonl|merchant-xml|merchant-tsv] [-o FILE] [-link URL]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	f, err := exporting.ParseFormat(*format)
+	if err != nil {
+		log.Fatalf("could not export products %v", err)
+	}
+
+	a := newAppFromFlags(loader)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("could not export products %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := exporting.NewExporter(a.productLister, *linkBase).Export(context.Background(), w, f); err != nil {
+		log.Fatalf("could not export products %v", err)
+	}
+	if err := a.close(); err != nil {
+		log.Fatalf("could not close storage %v", err)
+	}
+}
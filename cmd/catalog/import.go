@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/amelendres/go-catalog/config"
+	"github.com/amelendres/go-catalog/importing"
+)
+
+const (
+	productsKind  = "products"
+	discountsKind = "discounts"
+	couponsKind   = "coupons"
+)
+
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	loader := config.NewLoader(fs)
+	kind := fs.String("kind", productsKind, "file content: products, discounts or coupons")
+	mode := fs.String("mode", string(importing.UpsertMode), "import mode: upsert or replace")
+	format := fs.String("format", "", "file format: csv or json (default: file extension)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: catalog import [-kind products|discounts|coupons] [-mode upsert|replace] [-format csv|json] FILE")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+
+	m, err := importing.ParseMode(*mode)
+	if err != nil {
+		log.Fatalf("could not import %s %v", path, err)
+	}
+
+	a := newAppFromFlags(loader)
+	if a.config.Storage.Backend != config.FileBackend {
+		log.Fatalf("could not import %s: the %s storage backend is not persisted, use -storage %s -dsn FILE", path, a.config.Storage.Backend, config.FileBackend)
+	}
+	report, err := a.importFile(*kind, path, *format, m)
+	if report != nil {
+		for _, e := range report.Errors {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, e)
+		}
+	}
+	if err != nil {
+		log.Fatalf("could not import %s %v", path, err)
+	}
+	if err := a.close(); err != nil {
+		log.Fatalf("could not close storage %v", err)
+	}
+
+	fmt.Printf("imported %d %s from %s (%s)\n", report.Imported, *kind, path, m)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/amelendres/go-catalog/health"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// lifecycle runs the HTTP server, and the gRPC server when given, until ctx
+// is done and then shuts them down gracefully: it reports not ready, waits for
+// the load balancers to notice, stops accepting connections and drains the
+// in-flight requests.
+type lifecycle struct {
+	server          *http.Server
+	grpcServer      *grpc.Server
+	grpcListener    net.Listener
+	readiness       *health.Readiness
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+	logger          *zap.Logger
+}
+
+func (l *lifecycle) run(ctx context.Context, ln net.Listener) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- l.server.Serve(ln)
+	}()
+	grpcErrc := make(chan error, 1)
+	if l.grpcServer != nil {
+		go func() {
+			grpcErrc <- l.grpcServer.Serve(l.grpcListener)
+		}()
+		l.logger.Info("grpc started", zap.String("addr", l.grpcListener.Addr().String()))
+	}
+	l.readiness.SetReady(true)
+	l.logger.Info("catalog started", zap.String("addr", ln.Addr().String()))
+
+	select {
+	case err := <-errc:
+		l.readiness.SetReady(false)
+		l.stopGRPC()
+		return err
+	case err := <-grpcErrc:
+		l.readiness.SetReady(false)
+		_ = l.server.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	l.logger.Info("shutting down", zap.Duration("shutdown_delay", l.shutdownDelay), zap.Duration("shutdown_timeout", l.shutdownTimeout))
+	l.readiness.SetReady(false)
+
+	if l.shutdownDelay > 0 {
+		time.Sleep(l.shutdownDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+	if l.grpcServer != nil {
+		go func() {
+			<-shutdownCtx.Done()
+			l.grpcServer.Stop()
+		}()
+	}
+	if err := l.server.Shutdown(shutdownCtx); err != nil {
+		l.stopGRPC()
+		return err
+	}
+	if l.grpcServer != nil {
+		l.grpcServer.GracefulStop()
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (l *lifecycle) stopGRPC() {
+	if l.grpcServer != nil {
+		l.grpcServer.Stop()
+	}
+}
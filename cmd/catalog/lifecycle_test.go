@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/health"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestLifecycle_run_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fails listening %v", err)
+	}
+	readiness := health.NewReadiness()
+	l := &lifecycle{
+		server:          &http.Server{Handler: handler},
+		readiness:       readiness,
+		shutdownTimeout: 5 * time.Second,
+		logger:          zap.NewNop(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- l.run(ctx, ln)
+	}()
+
+	statusc := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			statusc <- 0
+			return
+		}
+		resp.Body.Close()
+		statusc <- resp.StatusCode
+	}()
+
+	<-started
+	assert.True(t, readiness.Ready())
+
+	cancel()
+	assert.Eventually(t, func() bool { return !readiness.Ready() }, time.Second, 10*time.Millisecond)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-statusc)
+	assert.NoError(t, <-done)
+
+	_, err = http.Get("http://" + ln.Addr().String())
+	assert.Error(t, err)
+}
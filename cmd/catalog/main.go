@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/config"
+	"github.com/amelendres/go-catalog/http/graphql"
 	"github.com/amelendres/go-catalog/http/rest"
-	"github.com/amelendres/go-catalog/listing"
-	"github.com/amelendres/go-catalog/pricing"
-	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/rpc"
+	"go.uber.org/zap"
 )
 
 const (
@@ -58,23 +66,137 @@ const (
 var (
 	givenCategoryDiscount = catalog.DiscountPercentage(30)
 	givenProductDiscount  = catalog.DiscountPercentage(15)
-	discounts             = []catalog.Discount{
+	givenDiscounts        = []catalog.Discount{
 		catalog.NewCategoryDiscount("boots", givenCategoryDiscount),
 		catalog.NewProductDiscount("000003", givenProductDiscount),
 	}
 )
 
 func main() {
-	productRepo := inmem.NewProductRepo(newProductsFromJSON(givenProductsJSON))
-	discountRepo := inmem.NewDiscountRepo(discounts)
-	pricingCalculater := pricing.NewCalculater(discountRepo)
-	productLister := listing.NewProductLister(productRepo, pricingCalculater)
+	cmd, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "serve":
+		runServe(args)
+	case "import":
+		runImport(args)
+	case "export":
+		runExport(args)
+	default:
+		log.Fatalf("unknown command %q, usage: catalog [serve|import|export]", cmd)
+	}
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	loader := config.NewLoader(fs)
+	_ = fs.Parse(args)
+
+	a := newAppFromFlags(loader)
+	if err := a.dispatchEvents(); err != nil {
+		log.Fatalf("could not start catalog %v", err)
+	}
+	opts := []rest.Option{
+		rest.WithPageSize(a.config.Pagination.DefaultLimit, a.config.Pagination.MaxLimit),
+		rest.WithHealthChecker(a.healthChecker()),
+		rest.WithMetrics(a.metrics),
+		rest.WithLogger(a.logger),
+		rest.WithTracer(a.tracer),
+		rest.WithPriceHistory(a.history),
+		rest.WithPricing(a.pricing),
+		rest.WithSimulator(a.simulator),
+		rest.WithCoupons(a.coupons),
+		rest.WithAdminToken(a.config.Admin.Token),
+		rest.WithETags(a.versions()...),
+		rest.WithCacheControl("/products", a.config.CacheControl.Products),
+		rest.WithCacheControl("/exports/products", a.config.CacheControl.Exports),
+		rest.WithGraphQL(graphql.NewHandler(
+			a.productLister,
+			a.products,
+			a.discounts,
+			graphql.WithPageSize(a.config.Pagination.DefaultLimit, a.config.Pagination.MaxLimit),
+		)),
+	}
+	if a.webhooks != nil {
+		if a.config.Admin.Token == "" {
+			a.logger.Warn("webhook subscriptions are not served without an admin token")
+		}
+		opts = append(opts, rest.WithWebhooks(a.webhooks))
+	}
+	if a.stream != nil {
+		opts = append(opts, rest.WithStream(a.stream, a.config.Stream.Heartbeat))
+	}
+	cs := rest.NewCatalogServer(a.productLister, opts...)
+
+	ln, err := net.Listen("tcp", a.config.ListenAddr)
+	if err != nil {
+		log.Fatalf("could not listen on %s %v", a.config.ListenAddr, err)
+	}
 
-	cs := rest.NewCatalogServer(productLister)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if err := http.ListenAndServe(":5000", cs); err != nil {
-		log.Fatalf("could not listen on port 5000 %v", err)
+	l := &lifecycle{
+		server: &http.Server{
+			Handler:      cs,
+			ConnContext:  rest.ConnContext,
+			ReadTimeout:  a.config.Server.ReadTimeout,
+			WriteTimeout: a.config.Server.WriteTimeout,
+			IdleTimeout:  a.config.Server.IdleTimeout,
+		},
+		readiness:       a.readiness,
+		shutdownDelay:   a.config.Server.ShutdownDelay,
+		shutdownTimeout: a.config.Server.ShutdownTimeout,
+		logger:          a.logger,
+	}
+	if a.stream != nil {
+		l.server.RegisterOnShutdown(func() { _ = a.stream.Close() })
+	}
+	if a.config.GRPCListenAddr != "" {
+		gln, err := net.Listen("tcp", a.config.GRPCListenAddr)
+		if err != nil {
+			log.Fatalf("could not listen on %s %v", a.config.GRPCListenAddr, err)
+		}
+		l.grpcServer = rpc.NewServer(rpc.NewCatalogServer(
+			a.productLister,
+			a.calculater,
+			rpc.WithPageSize(a.config.Pagination.DefaultLimit, a.config.Pagination.MaxLimit),
+		))
+		rpc.RegisterPricing(l.grpcServer, rpc.NewPricingServer(a.pricing))
+		l.grpcListener = gln
+	}
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+		if a.dispatcher != nil {
+			a.dispatcher.Run(ctx)
+		}
+	}()
+	serveErr := l.run(ctx, ln)
+	stop()
+	<-dispatched
+	if err := a.close(); err != nil {
+		a.logger.Error("could not close storage", zap.Error(err))
+	}
+	if serveErr != nil {
+		a.logger.Fatal("could not serve", zap.String("addr", a.config.ListenAddr), zap.Error(serveErr))
+	}
+	a.logger.Info("catalog stopped")
+}
+
+func newAppFromFlags(loader *config.Loader) *app {
+	cfg, err := loader.Load(os.LookupEnv)
+	if err != nil {
+		log.Fatalf("could not load config %v", err)
+	}
+	a, err := newApp(cfg)
+	if err != nil {
+		log.Fatalf("could not start catalog %v", err)
 	}
+	return a
 }
 
 func newProductsFromJSON(jsonStr string) []*catalog.Product {
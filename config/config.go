@@ -0,0 +1,403 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	InmemBackend = "inmem"
+	FileBackend  = "file"
+
+	NoPublisher   = "none"
+	FilePublisher = "file"
+
+	configEnv = "CATALOG_CONFIG"
+)
+
+type Config struct {
+	ListenAddr     string       `yaml:"listen_addr"`
+	GRPCListenAddr string       `yaml:"grpc_listen_addr"`
+	Server         Server       `yaml:"server"`
+	Admin          Admin        `yaml:"admin"`
+	Storage        Storage      `yaml:"storage"`
+	Seed           Seed         `yaml:"seed"`
+	Pagination     Pagination   `yaml:"pagination"`
+	Pricing        Pricing      `yaml:"pricing"`
+	Tracing        Tracing      `yaml:"tracing"`
+	CacheControl   CacheControl `yaml:"cache_control"`
+	PriceCache     PriceCache   `yaml:"price_cache"`
+	Events         Events       `yaml:"events"`
+	Webhooks       Webhooks     `yaml:"webhooks"`
+	Stream         Stream       `yaml:"stream"`
+}
+
+type Server struct {
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// Admin holds the bearer token of the admin routes, which are not served
+// without one.
+type Admin struct {
+	Token string `yaml:"token"`
+}
+
+type Storage struct {
+	Backend string `yaml:"backend"`
+	DSN     string `yaml:"dsn"`
+}
+
+type Seed struct {
+	Products  string `yaml:"products"`
+	Discounts string `yaml:"discounts"`
+	Coupons   string `yaml:"coupons"`
+}
+
+type Pagination struct {
+	DefaultLimit int `yaml:"default_limit"`
+	MaxLimit     int `yaml:"max_limit"`
+}
+
+// Pricing configures the pricing context, served in-process unless Endpoint
+// sets the gRPC address of a remote pricing service.
+type Pricing struct {
+	Strategy string `yaml:"strategy"`
+	Rounding string `yaml:"rounding"`
+	Endpoint string `yaml:"endpoint"`
+}
+
+type Tracing struct {
+	Exporter string `yaml:"exporter"`
+	Endpoint string `yaml:"endpoint"`
+}
+
+// CacheControl holds the Cache-Control header of each cacheable route.
+type CacheControl struct {
+	Products string `yaml:"products"`
+	Exports  string `yaml:"exports"`
+}
+
+// PriceCache bounds the in-process cache of discounted prices, a zero size
+// disables it.
+type PriceCache struct {
+	Size int           `yaml:"size"`
+	TTL  time.Duration `yaml:"ttl"`
+}
+
+// Events selects where the catalog change events are published, the file
+// publisher appending them as JSON lines to Path.
+type Events struct {
+	Publisher string        `yaml:"publisher"`
+	Path      string        `yaml:"path"`
+	Interval  time.Duration `yaml:"interval"`
+}
+
+// Webhooks enables the webhook subscriptions, fed by the catalog events, and
+// bounds the delivery retries.
+type Webhooks struct {
+	Enabled     bool          `yaml:"enabled"`
+	MaxAttempts int           `yaml:"max_attempts"`
+	Backoff     time.Duration `yaml:"backoff"`
+	// AllowPrivate allows subscribing loopback, link-local and private
+	// addresses.
+	AllowPrivate bool `yaml:"allow_private"`
+}
+
+// Stream enables the Server-Sent Events stream of product updates, fed by the
+// catalog events.
+type Stream struct {
+	Enabled   bool          `yaml:"enabled"`
+	Heartbeat time.Duration `yaml:"heartbeat"`
+}
+
+func Default() *Config {
+	return &Config{
+		ListenAddr:     ":5000",
+		GRPCListenAddr: ":5001",
+		Server: Server{
+			ReadTimeout:     5 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Storage:    Storage{Backend: InmemBackend},
+		Pagination: Pagination{DefaultLimit: 5, MaxLimit: 100},
+		Pricing:    Pricing{Strategy: "highest", Rounding: "half-up"},
+		Tracing:    Tracing{Exporter: "none"},
+		CacheControl: CacheControl{
+			Products: "public, max-age=60",
+			Exports:  "public, max-age=300",
+		},
+		PriceCache: PriceCache{Size: 10000, TTL: 5 * time.Minute},
+		Events:     Events{Publisher: NoPublisher, Interval: time.Second},
+		Webhooks:   Webhooks{MaxAttempts: 5, Backoff: time.Second},
+		Stream:     Stream{Heartbeat: 15 * time.Second},
+	}
+}
+
+func (c *Config) Validate() error {
+	switch c.Storage.Backend {
+	case InmemBackend:
+	case FileBackend:
+		if c.Storage.DSN == "" {
+			return errors.New("storage dsn is required by the file backend")
+		}
+	default:
+		return fmt.Errorf("unknown storage backend %q", c.Storage.Backend)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("shutdown timeout must be positive, got %s", c.Server.ShutdownTimeout)
+	}
+	if c.Pagination.DefaultLimit < 1 {
+		return fmt.Errorf("default page size must be positive, got %d", c.Pagination.DefaultLimit)
+	}
+	if c.Pagination.MaxLimit < c.Pagination.DefaultLimit {
+		return fmt.Errorf("max page size %d is lower than the default page size %d", c.Pagination.MaxLimit, c.Pagination.DefaultLimit)
+	}
+	switch c.Events.Publisher {
+	case NoPublisher:
+	case FilePublisher:
+		if c.Events.Path == "" {
+			return errors.New("events path is required by the file publisher")
+		}
+	default:
+		return fmt.Errorf("unknown events publisher %q", c.Events.Publisher)
+	}
+	if c.Events.Interval <= 0 {
+		return fmt.Errorf("events interval must be positive, got %s", c.Events.Interval)
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		return fmt.Errorf("webhook attempts must be positive, got %d", c.Webhooks.MaxAttempts)
+	}
+	if c.Stream.Heartbeat <= 0 {
+		return fmt.Errorf("stream heartbeat must be positive, got %s", c.Stream.Heartbeat)
+	}
+	if c.PriceCache.Size < 0 {
+		return fmt.Errorf("price cache size must not be negative, got %d", c.PriceCache.Size)
+	}
+	return nil
+}
+
+// setting binds a configuration value to its flag and environment variable.
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+var settings = []setting{
+	{"addr", "CATALOG_LISTEN_ADDR", "listen address", func(c *Config, v string) error {
+		c.ListenAddr = v
+		return nil
+	}},
+	{"grpc-addr", "CATALOG_GRPC_LISTEN_ADDR", "gRPC listen address, empty disables the gRPC API", func(c *Config, v string) error {
+		c.GRPCListenAddr = v
+		return nil
+	}},
+	{"admin-token", "CATALOG_ADMIN_TOKEN", "bearer token of the admin routes, empty disables them", func(c *Config, v string) error {
+		c.Admin.Token = v
+		return nil
+	}},
+	{"read-timeout", "CATALOG_READ_TIMEOUT", "max duration for reading a request", func(c *Config, v string) (err error) {
+		c.Server.ReadTimeout, err = time.ParseDuration(v)
+		return err
+	}},
+	{"write-timeout", "CATALOG_WRITE_TIMEOUT", "max duration for writing a response", func(c *Config, v string) (err error) {
+		c.Server.WriteTimeout, err = time.ParseDuration(v)
+		return err
+	}},
+	{"idle-timeout", "CATALOG_IDLE_TIMEOUT", "max keep-alive idle duration", func(c *Config, v string) (err error) {
+		c.Server.IdleTimeout, err = time.ParseDuration(v)
+		return err
+	}},
+	{"shutdown-delay", "CATALOG_SHUTDOWN_DELAY", "time between reporting not ready and closing the listener", func(c *Config, v string) (err error) {
+		c.Server.ShutdownDelay, err = time.ParseDuration(v)
+		return err
+	}},
+	{"shutdown-timeout", "CATALOG_SHUTDOWN_TIMEOUT", "max time to drain in-flight requests", func(c *Config, v string) (err error) {
+		c.Server.ShutdownTimeout, err = time.ParseDuration(v)
+		return err
+	}},
+	{"storage", "CATALOG_STORAGE_BACKEND", "storage backend: inmem or file", func(c *Config, v string) error {
+		c.Storage.Backend = v
+		return nil
+	}},
+	{"dsn", "CATALOG_STORAGE_DSN", "storage data source name", func(c *Config, v string) error {
+		c.Storage.DSN = v
+		return nil
+	}},
+	{"seed-products", "CATALOG_SEED_PRODUCTS", "products file loaded on start", func(c *Config, v string) error {
+		c.Seed.Products = v
+		return nil
+	}},
+	{"seed-discounts", "CATALOG_SEED_DISCOUNTS", "discounts file loaded on start", func(c *Config, v string) error {
+		c.Seed.Discounts = v
+		return nil
+	}},
+	{"seed-coupons", "CATALOG_SEED_COUPONS", "coupons file loaded on start", func(c *Config, v string) error {
+		c.Seed.Coupons = v
+		return nil
+	}},
+	{"page-size", "CATALOG_PAGE_SIZE", "default page size", func(c *Config, v string) (err error) {
+		c.Pagination.DefaultLimit, err = strconv.Atoi(v)
+		return err
+	}},
+	{"max-page-size", "CATALOG_MAX_PAGE_SIZE", "max page size", func(c *Config, v string) (err error) {
+		c.Pagination.MaxLimit, err = strconv.Atoi(v)
+		return err
+	}},
+	{"pricing-strategy", "CATALOG_PRICING_STRATEGY", "pricing strategy: highest or product-first", func(c *Config, v string) error {
+		c.Pricing.Strategy = v
+		return nil
+	}},
+	{"pricing-rounding", "CATALOG_PRICING_ROUNDING", "rounding of discounted prices: half-up, half-even or price-point", func(c *Config, v string) error {
+		c.Pricing.Rounding = v
+		return nil
+	}},
+	{"pricing-endpoint", "CATALOG_PRICING_ENDPOINT", "gRPC address of a remote pricing service, empty prices in-process", func(c *Config, v string) error {
+		c.Pricing.Endpoint = v
+		return nil
+	}},
+	{"tracing-exporter", "CATALOG_TRACING_EXPORTER", "tracing exporter: none, stdout or otlp", func(c *Config, v string) error {
+		c.Tracing.Exporter = v
+		return nil
+	}},
+	{"tracing-endpoint", "CATALOG_TRACING_ENDPOINT", "OTLP/HTTP collector host:port", func(c *Config, v string) error {
+		c.Tracing.Endpoint = v
+		return nil
+	}},
+	{"products-cache-control", "CATALOG_PRODUCTS_CACHE_CONTROL", "Cache-Control header of GET /products", func(c *Config, v string) error {
+		c.CacheControl.Products = v
+		return nil
+	}},
+	{"price-cache-size", "CATALOG_PRICE_CACHE_SIZE", "max cached discounted prices, 0 disables the cache", func(c *Config, v string) (err error) {
+		c.PriceCache.Size, err = strconv.Atoi(v)
+		return err
+	}},
+	{"price-cache-ttl", "CATALOG_PRICE_CACHE_TTL", "time a discounted price is cached", func(c *Config, v string) (err error) {
+		c.PriceCache.TTL, err = time.ParseDuration(v)
+		return err
+	}},
+	{"events-publisher", "CATALOG_EVENTS_PUBLISHER", "catalog events publisher: none or file", func(c *Config, v string) error {
+		c.Events.Publisher = v
+		return nil
+	}},
+	{"events-path", "CATALOG_EVENTS_PATH", "file the file publisher appends events to", func(c *Config, v string) error {
+		c.Events.Path = v
+		return nil
+	}},
+	{"events-interval", "CATALOG_EVENTS_INTERVAL", "time between outbox dispatches", func(c *Config, v string) (err error) {
+		c.Events.Interval, err = time.ParseDuration(v)
+		return err
+	}},
+	{"webhooks", "CATALOG_WEBHOOKS_ENABLED", "serve webhook subscriptions of the price changes", func(c *Config, v string) (err error) {
+		c.Webhooks.Enabled, err = strconv.ParseBool(v)
+		return err
+	}},
+	{"webhook-attempts", "CATALOG_WEBHOOK_ATTEMPTS", "delivery attempts before a webhook is dead-lettered", func(c *Config, v string) (err error) {
+		c.Webhooks.MaxAttempts, err = strconv.Atoi(v)
+		return err
+	}},
+	{"webhook-backoff", "CATALOG_WEBHOOK_BACKOFF", "wait before the first webhook retry, doubled on every retry", func(c *Config, v string) (err error) {
+		c.Webhooks.Backoff, err = time.ParseDuration(v)
+		return err
+	}},
+	{"webhook-allow-private", "CATALOG_WEBHOOK_ALLOW_PRIVATE", "allow webhooks to loopback, link-local and private addresses", func(c *Config, v string) (err error) {
+		c.Webhooks.AllowPrivate, err = strconv.ParseBool(v)
+		return err
+	}},
+	{"stream", "CATALOG_STREAM_ENABLED", "serve the product updates stream", func(c *Config, v string) (err error) {
+		c.Stream.Enabled, err = strconv.ParseBool(v)
+		return err
+	}},
+	{"stream-heartbeat", "CATALOG_STREAM_HEARTBEAT", "time between stream heartbeats", func(c *Config, v string) (err error) {
+		c.Stream.Heartbeat, err = time.ParseDuration(v)
+		return err
+	}},
+	{"exports-cache-control", "CATALOG_EXPORTS_CACHE_CONTROL", "Cache-Control header of GET /exports/products", func(c *Config, v string) error {
+		c.CacheControl.Exports = v
+		return nil
+	}},
+}
+
+// Loader resolves the configuration from defaults, an optional YAML file,
+// environment variables and flags, each one overriding the previous.
+type Loader struct {
+	fs   *flag.FlagSet
+	file *string
+}
+
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{fs: fs}
+	l.file = fs.String("config", "", "YAML configuration file (env "+configEnv+")")
+	for _, s := range settings {
+		fs.String(s.flag, "", s.usage+" (env "+s.env+")")
+	}
+	return l
+}
+
+// Load reads the configuration file, then the environment through lookupEnv,
+// usually os.LookupEnv, then the flags. A variable set to an empty value
+// overrides the setting, so CATALOG_GRPC_LISTEN_ADDR= disables the gRPC API.
+func (l *Loader) Load(lookupEnv func(string) (string, bool)) (*Config, error) {
+	c := Default()
+
+	file, _ := lookupEnv(configEnv)
+	if *l.file != "" {
+		file = *l.file
+	}
+	if file != "" {
+		if err := loadFile(c, file); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := lookupEnv(s.env); ok {
+			if err := s.set(c, v); err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", s.env, v, err)
+			}
+		}
+	}
+
+	var err error
+	l.fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name && err == nil {
+				if e := s.set(c, f.Value.String()); e != nil {
+					err = fmt.Errorf("invalid -%s %q: %w", s.flag, f.Value, e)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func loadFile(c *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read config %w", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	return nil
+}
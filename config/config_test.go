@@ -0,0 +1,183 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const givenConfigYAML = `
+listen_addr: ":8080"
+grpc_listen_addr: ":8081"
+server:
+  read_timeout: 2s
+  write_timeout: 1m
+  idle_timeout: 90s
+  shutdown_delay: 5s
+  shutdown_timeout: 20s
+admin:
+  token: s3cr3t
+storage:
+  backend: file
+  dsn: /var/lib/catalog/catalog.json
+pagination:
+  default_limit: 10
+  max_limit: 50
+pricing:
+  strategy: product-first
+  rounding: price-point
+  endpoint: pricing:5001
+tracing:
+  exporter: otlp
+  endpoint: otel-collector:4318
+cache_control:
+  products: public, max-age=30
+  exports: no-cache
+price_cache:
+  size: 500
+  ttl: 1m
+events:
+  publisher: file
+  path: /var/log/catalog/events.jsonl
+  interval: 2s
+webhooks:
+  enabled: true
+  max_attempts: 3
+  backoff: 500ms
+  allow_private: true
+stream:
+  enabled: true
+  heartbeat: 30s
+`
+
+func TestLoader_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	if err := os.WriteFile(path, []byte(givenConfigYAML), 0o600); err != nil {
+		t.Fatalf("fails writing config file %v", err)
+	}
+
+	fromFile := &config.Config{
+		ListenAddr:     ":8080",
+		GRPCListenAddr: ":8081",
+		Server: config.Server{
+			ReadTimeout:     2 * time.Second,
+			WriteTimeout:    time.Minute,
+			IdleTimeout:     90 * time.Second,
+			ShutdownDelay:   5 * time.Second,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Admin:        config.Admin{Token: "s3cr3t"},
+		Storage:      config.Storage{Backend: config.FileBackend, DSN: "/var/lib/catalog/catalog.json"},
+		Pagination:   config.Pagination{DefaultLimit: 10, MaxLimit: 50},
+		Pricing:      config.Pricing{Strategy: "product-first", Rounding: "price-point", Endpoint: "pricing:5001"},
+		Tracing:      config.Tracing{Exporter: "otlp", Endpoint: "otel-collector:4318"},
+		CacheControl: config.CacheControl{Products: "public, max-age=30", Exports: "no-cache"},
+		PriceCache:   config.PriceCache{Size: 500, TTL: time.Minute},
+		Events:       config.Events{Publisher: config.FilePublisher, Path: "/var/log/catalog/events.jsonl", Interval: 2 * time.Second},
+		Webhooks:     config.Webhooks{Enabled: true, MaxAttempts: 3, Backoff: 500 * time.Millisecond, AllowPrivate: true},
+		Stream:       config.Stream{Enabled: true, Heartbeat: 30 * time.Second},
+	}
+	fromEnv := *fromFile
+	fromEnv.ListenAddr = ":9090"
+	fromEnv.Pagination.MaxLimit = 20
+	fromFlags := fromEnv
+	fromFlags.ListenAddr = ":7070"
+	fromFlags.Server.ShutdownDelay = 10 * time.Second
+	withoutGRPC := config.Default()
+	withoutGRPC.GRPCListenAddr = ""
+
+	tests := map[string]struct {
+		args    []string
+		env     map[string]string
+		want    *config.Config
+		wantErr bool
+	}{
+		"Defaults": {
+			want: config.Default(),
+		},
+		"File": {
+			args: []string{"-config", path},
+			want: fromFile,
+		},
+		"File from env": {
+			env:  map[string]string{"CATALOG_CONFIG": path},
+			want: fromFile,
+		},
+		"Env overrides file": {
+			args: []string{"-config", path},
+			env:  map[string]string{"CATALOG_LISTEN_ADDR": ":9090", "CATALOG_MAX_PAGE_SIZE": "20"},
+			want: &fromEnv,
+		},
+		"Flags override env": {
+			args: []string{"-config", path, "-addr", ":7070", "-shutdown-delay", "10s"},
+			env:  map[string]string{"CATALOG_LISTEN_ADDR": ":9090", "CATALOG_MAX_PAGE_SIZE": "20"},
+			want: &fromFlags,
+		},
+		"Empty env disables gRPC": {
+			env:  map[string]string{"CATALOG_GRPC_LISTEN_ADDR": ""},
+			want: withoutGRPC,
+		},
+		"Invalid page size": {
+			env:     map[string]string{"CATALOG_PAGE_SIZE": "ten"},
+			wantErr: true,
+		},
+		"Invalid duration": {
+			args:    []string{"-read-timeout", "5"},
+			wantErr: true,
+		},
+		"Max page size lower than default": {
+			args:    []string{"-page-size", "10", "-max-page-size", "5"},
+			wantErr: true,
+		},
+		"Negative price cache size": {
+			args:    []string{"-price-cache-size", "-1"},
+			wantErr: true,
+		},
+		"File publisher without path": {
+			args:    []string{"-events-publisher", "file"},
+			wantErr: true,
+		},
+		"No webhook attempts": {
+			args:    []string{"-webhook-attempts", "0"},
+			wantErr: true,
+		},
+		"No stream heartbeat": {
+			args:    []string{"-stream-heartbeat", "0s"},
+			wantErr: true,
+		},
+		"File backend without dsn": {
+			args:    []string{"-storage", "file"},
+			wantErr: true,
+		},
+		"Unknown backend": {
+			args:    []string{"-storage", "mongo"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		loader := config.NewLoader(fs)
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatalf("%s: fails parsing flags %v", name, err)
+		}
+
+		got, err := loader.Load(func(key string) (string, bool) {
+			v, ok := tc.env[key]
+			return v, ok
+		})
+
+		if tc.wantErr {
+			assert.Error(t, err, name)
+			assert.Nil(t, got, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, tc.want, got, name)
+	}
+}
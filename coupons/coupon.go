@@ -0,0 +1,93 @@
+package coupons
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+var (
+	ErrCouponNotFound  = catalog.NewNotFoundError("promo code not found")
+	ErrCouponExhausted = catalog.NewConflictError("promo code has no uses left")
+)
+
+// Coupon is a promo code discounting a percentage of the eligible products
+// while valid, up to MaxUses redemptions, unlimited when 0.
+type Coupon struct {
+	Code        string                     `json:"code"`
+	Percentage  catalog.DiscountPercentage `json:"percentage"`
+	ValidFrom   *time.Time                 `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time                 `json:"valid_until,omitempty"`
+	MaxUses     int                        `json:"max_uses,omitempty"`
+	Uses        int                        `json:"uses"`
+	Eligibility Eligibility                `json:"eligibility"`
+}
+
+// Eligibility restricts the products a coupon applies to: any of its
+// categories or SKUs, when set, priced at least MinPrice before discounts.
+type Eligibility struct {
+	Categories []catalog.Category `json:"categories,omitempty"`
+	SKUs       []catalog.SKU      `json:"skus,omitempty"`
+	MinPrice   catalog.Price      `json:"min_price,omitempty"`
+}
+
+// Usable returns why the coupon cannot be used at the given time, nothing
+// when it can.
+func (c Coupon) Usable(at time.Time) []string {
+	var reasons []string
+	if c.ValidFrom != nil && at.Before(*c.ValidFrom) {
+		reasons = append(reasons, fmt.Sprintf("not valid until %s", c.ValidFrom.Format(time.RFC3339)))
+	}
+	if c.ValidUntil != nil && !at.Before(*c.ValidUntil) {
+		reasons = append(reasons, fmt.Sprintf("expired at %s", c.ValidUntil.Format(time.RFC3339)))
+	}
+	if c.MaxUses > 0 && c.Uses >= c.MaxUses {
+		reasons = append(reasons, fmt.Sprintf("used %d of %d times", c.Uses, c.MaxUses))
+	}
+	return reasons
+}
+
+// Eligible returns why the coupon does not apply to p, nothing when it does.
+func (c Coupon) Eligible(p catalog.Product) []string {
+	var reasons []string
+	e := c.Eligibility
+	if len(e.Categories) > 0 || len(e.SKUs) > 0 {
+		if !hasCategory(e.Categories, p.Category) && !hasSKU(e.SKUs, p.SKU) {
+			reasons = append(reasons, fmt.Sprintf("product %s of category %s is not eligible", p.SKU, p.Category))
+		}
+	}
+	if p.Price < e.MinPrice {
+		reasons = append(reasons, fmt.Sprintf("price %d is below the minimum of %d", p.Price, e.MinPrice))
+	}
+	return reasons
+}
+
+func hasCategory(categories []catalog.Category, c catalog.Category) bool {
+	for _, category := range categories {
+		if category == c {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSKU(skus []catalog.SKU, s catalog.SKU) bool {
+	for _, sku := range skus {
+		if sku == s {
+			return true
+		}
+	}
+	return false
+}
+
+type Repository interface {
+	// Save adds the coupons, replacing the ones with the same code but for
+	// their uses.
+	Save(ctx context.Context, cs []Coupon) error
+	Find(ctx context.Context, code string) (*Coupon, error)
+	// Redeem counts a use of the coupon, failing with ErrCouponExhausted
+	// when it has no uses left.
+	Redeem(ctx context.Context, code string) (*Coupon, error)
+}
@@ -0,0 +1,124 @@
+package coupons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+// Service manages the promo codes and tells whether they apply to a product.
+// Codes are matched ignoring case.
+type Service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+type Option func(s *Service)
+
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
+}
+
+func NewService(repo Repository, opts ...Option) *Service {
+	s := &Service{repo: repo, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Validation tells whether a promo code applies and, when not, why.
+type Validation struct {
+	Code       string                     `json:"code"`
+	SKU        catalog.SKU                `json:"sku,omitempty"`
+	At         time.Time                  `json:"at"`
+	Percentage catalog.DiscountPercentage `json:"percentage"`
+	Applicable bool                       `json:"applicable"`
+	Reasons    []string                   `json:"reasons"`
+}
+
+func normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Import saves the coupons of a JSON array, the ones already stored keeping
+// their uses, and returns how many were imported. Nothing is saved when any
+// coupon is invalid.
+func (s *Service) Import(ctx context.Context, r io.Reader) (int, error) {
+	var cs []Coupon
+	if err := json.NewDecoder(r).Decode(&cs); err != nil {
+		return 0, catalog.NewInvalidArgumentError("coupons must be a JSON array, %v", err)
+	}
+	for i := range cs {
+		if err := validate(&cs[i]); err != nil {
+			return 0, fmt.Errorf("coupon %d %w", i+1, err)
+		}
+	}
+	if err := s.repo.Save(ctx, cs); err != nil {
+		return 0, err
+	}
+	return len(cs), nil
+}
+
+// validate normalizes the code of a new coupon, without uses, and checks its
+// fields.
+func validate(c *Coupon) error {
+	c.Code = normalize(c.Code)
+	if c.Code == "" {
+		return catalog.NewInvalidArgumentError("code is required")
+	}
+	if c.Percentage <= 0 || c.Percentage > 100 {
+		return catalog.NewInvalidArgumentError("percentage must be between 1 and 100, got %d", c.Percentage)
+	}
+	if c.ValidFrom != nil && c.ValidUntil != nil && !c.ValidUntil.After(*c.ValidFrom) {
+		return catalog.NewInvalidArgumentError("valid_until must be after valid_from")
+	}
+	if c.MaxUses < 0 {
+		return catalog.NewInvalidArgumentError("max_uses must not be negative, got %d", c.MaxUses)
+	}
+	if c.Eligibility.MinPrice < 0 {
+		return catalog.NewInvalidArgumentError("min_price must not be negative, got %d", c.Eligibility.MinPrice)
+	}
+	c.Uses = 0
+	return nil
+}
+
+func (s *Service) Find(ctx context.Context, code string) (*Coupon, error) {
+	return s.repo.Find(ctx, normalize(code))
+}
+
+// Validate tells whether the code can be used now and, when p is given,
+// whether it applies to it.
+func (s *Service) Validate(ctx context.Context, code string, p *catalog.Product) (*Validation, error) {
+	c, err := s.Find(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	v := &Validation{Code: c.Code, At: s.now(), Percentage: c.Percentage}
+	v.Reasons = append([]string{}, c.Usable(v.At)...)
+	if p != nil {
+		v.SKU = p.SKU
+		v.Reasons = append(v.Reasons, c.Eligible(*p)...)
+	}
+	v.Applicable = len(v.Reasons) == 0
+	return v, nil
+}
+
+// Redeem counts a use of a code that can be used now.
+func (s *Service) Redeem(ctx context.Context, code string) (*Coupon, error) {
+	v, err := s.Validate(ctx, code, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !v.Applicable {
+		return nil, catalog.NewConflictError("promo code %s cannot be used: %s", v.Code, strings.Join(v.Reasons, ", "))
+	}
+	return s.repo.Redeem(ctx, v.Code)
+}
@@ -0,0 +1,145 @@
+package coupons_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var givenNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func at(d time.Duration) *time.Time {
+	t := givenNow.Add(d)
+	return &t
+}
+
+func TestService_Validate(t *testing.T) {
+	boots := catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)
+	sandals := catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500)
+	s := coupons.NewService(inmem.NewCouponRepo(
+		coupons.Coupon{Code: "BOOTS20", Percentage: 20, Eligibility: coupons.Eligibility{Categories: []catalog.Category{"boots"}, MinPrice: 80000}},
+		coupons.Coupon{Code: "SANDAL", Percentage: 20, Eligibility: coupons.Eligibility{SKUs: []catalog.SKU{"000004"}}},
+		coupons.Coupon{Code: "SUMMER", Percentage: 15, ValidFrom: at(24 * time.Hour)},
+		coupons.Coupon{Code: "WINTER", Percentage: 15, ValidUntil: at(-time.Hour), MaxUses: 2, Uses: 2},
+	), coupons.WithClock(func() time.Time { return givenNow }))
+
+	tests := map[string]struct {
+		code    string
+		product *catalog.Product
+		want    []string
+		wantErr error
+	}{
+		"Eligible category":  {code: "boots20", product: boots, want: []string{}},
+		"Eligible SKU":       {code: "SANDAL", product: sandals, want: []string{}},
+		"Without product":    {code: "BOOTS20", want: []string{}},
+		"Ineligible product": {code: "BOOTS20", product: sandals, want: []string{"product 000004 of category sandals is not eligible", "price 79500 is below the minimum of 80000"}},
+		"Not valid yet":      {code: "SUMMER", product: boots, want: []string{"not valid until 2026-03-02T12:00:00Z"}},
+		"Expired and used":   {code: "WINTER", want: []string{"expired at 2026-03-01T11:00:00Z", "used 2 of 2 times"}},
+		"Unknown code":       {code: "AUTUMN", wantErr: coupons.ErrCouponNotFound},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.Validate(context.Background(), tc.code, tc.product)
+
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Reasons)
+			assert.Equal(t, len(tc.want) == 0, got.Applicable)
+			assert.Equal(t, givenNow, got.At)
+		})
+	}
+}
+
+func TestService_Import(t *testing.T) {
+	tests := map[string]struct {
+		json    string
+		want    []coupons.Coupon
+		wantErr error
+	}{
+		"New and stored coupons": {
+			json: `[{"code":"spring10","percentage":10},{"code":"welcome","percentage":15,"max_uses":10,"uses":9}]`,
+			want: []coupons.Coupon{
+				{Code: "SPRING10", Percentage: 10},
+				{Code: "WELCOME", Percentage: 15, MaxUses: 10, Uses: 2},
+			},
+		},
+		"Invalid percentage": {
+			json:    `[{"code":"spring10","percentage":10},{"code":"welcome","percentage":101}]`,
+			want:    []coupons.Coupon{{Code: "WELCOME", Percentage: 5, Uses: 2}},
+			wantErr: catalog.ErrInvalidArgument,
+		},
+		"Without code": {
+			json:    `[{"percentage":10}]`,
+			want:    []coupons.Coupon{{Code: "WELCOME", Percentage: 5, Uses: 2}},
+			wantErr: catalog.ErrInvalidArgument,
+		},
+		"Ending before it starts": {
+			json:    `[{"code":"spring10","percentage":10,"valid_from":"2026-03-02T00:00:00Z","valid_until":"2026-03-01T00:00:00Z"}]`,
+			want:    []coupons.Coupon{{Code: "WELCOME", Percentage: 5, Uses: 2}},
+			wantErr: catalog.ErrInvalidArgument,
+		},
+		"Negative max uses": {
+			json:    `[{"code":"spring10","percentage":10,"max_uses":-1}]`,
+			want:    []coupons.Coupon{{Code: "WELCOME", Percentage: 5, Uses: 2}},
+			wantErr: catalog.ErrInvalidArgument,
+		},
+		"Negative min price": {
+			json:    `[{"code":"spring10","percentage":10,"eligibility":{"min_price":-1}}]`,
+			want:    []coupons.Coupon{{Code: "WELCOME", Percentage: 5, Uses: 2}},
+			wantErr: catalog.ErrInvalidArgument,
+		},
+		"Not an array": {
+			json:    `{"code":"spring10","percentage":10}`,
+			want:    []coupons.Coupon{{Code: "WELCOME", Percentage: 5, Uses: 2}},
+			wantErr: catalog.ErrInvalidArgument,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := inmem.NewCouponRepo(coupons.Coupon{Code: "WELCOME", Percentage: 5, Uses: 2})
+			s := coupons.NewService(repo)
+
+			n, err := s.Import(context.Background(), strings.NewReader(tc.json))
+
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, len(tc.want), n)
+			}
+			assert.Equal(t, tc.want, repo.All(), "stored coupons keep their uses")
+		})
+	}
+}
+
+func TestService_Redeem(t *testing.T) {
+	s := coupons.NewService(inmem.NewCouponRepo(
+		coupons.Coupon{Code: "TWICE", Percentage: 10, MaxUses: 2},
+		coupons.Coupon{Code: "EXPIRED", Percentage: 10, ValidUntil: at(-time.Hour)},
+	), coupons.WithClock(func() time.Time { return givenNow }))
+	ctx := context.Background()
+
+	for uses := 1; uses <= 2; uses++ {
+		got, err := s.Redeem(ctx, "twice")
+		require.NoError(t, err)
+		assert.Equal(t, uses, got.Uses)
+	}
+	_, err := s.Redeem(ctx, "TWICE")
+	assert.ErrorIs(t, err, catalog.ErrConflict)
+	_, err = s.Redeem(ctx, "EXPIRED")
+	assert.ErrorIs(t, err, catalog.ErrConflict)
+	_, err = s.Redeem(ctx, "UNKNOWN")
+	assert.ErrorIs(t, err, coupons.ErrCouponNotFound)
+}
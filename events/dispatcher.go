@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultInterval  = time.Second
+	defaultBatchSize = 100
+)
+
+// Outbox holds the events emitted by the catalog write side until they are
+// dispatched. Events are acknowledged in order, up to an ID.
+type Outbox interface {
+	Pending(ctx context.Context, limit int) ([]catalog.Event, error)
+	Ack(ctx context.Context, id uint64) error
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e catalog.Event) error
+}
+
+// Dispatcher publishes the outbox events in order, at least once: an event is
+// acknowledged only after it is published, and retried on the next pass when
+// publishing fails.
+type Dispatcher struct {
+	outbox    Outbox
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+type Option func(d *Dispatcher)
+
+func WithInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.interval = interval
+	}
+}
+
+func WithLogger(l *zap.Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = l
+	}
+}
+
+func NewDispatcher(o Outbox, p Publisher, opts ...Option) *Dispatcher {
+	d := &Dispatcher{outbox: o, publisher: p, interval: defaultInterval, batchSize: defaultBatchSize, logger: zap.NewNop()}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Run dispatches the pending events every interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Warn("could not dispatch events", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch publishes every pending event and returns how many were published.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		pending, err := d.outbox.Pending(ctx, d.batchSize)
+		if err != nil || len(pending) == 0 {
+			return published, err
+		}
+		// the batch is acknowledged once, up to the last published event.
+		var lastID uint64
+		var publishErr error
+		for _, e := range pending {
+			if publishErr = d.publisher.Publish(ctx, e); publishErr != nil {
+				break
+			}
+			lastID = e.ID
+			published++
+		}
+		if lastID > 0 {
+			if err := d.outbox.Ack(ctx, lastID); err != nil {
+				return published, err
+			}
+		}
+		if publishErr != nil {
+			return published, publishErr
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/events"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+type flakyPublisher struct {
+	events.MemoryPublisher
+	failures int
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, e catalog.Event) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, e)
+}
+
+// failingOncePublisher fails the first time it publishes the event failID.
+type failingOncePublisher struct {
+	events.MemoryPublisher
+	failID uint64
+}
+
+func (p *failingOncePublisher) Publish(ctx context.Context, e catalog.Event) error {
+	if e.ID == p.failID {
+		p.failID = 0
+		return errors.New("broker unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, e)
+}
+
+type recordingOutbox struct {
+	*inmem.Outbox
+	acks []uint64
+}
+
+func (o *recordingOutbox) Ack(ctx context.Context, id uint64) error {
+	o.acks = append(o.acks, id)
+	return o.Outbox.Ack(ctx, id)
+}
+
+func eventTypes(es []catalog.Event) []catalog.EventType {
+	var types []catalog.EventType
+	for _, e := range es {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	outbox := inmem.NewOutbox()
+	productRepo := inmem.NewProductRepo(nil, inmem.WithOutbox(outbox))
+	discountRepo := inmem.NewDiscountRepo(nil, inmem.WithOutbox(outbox))
+
+	_ = productRepo.Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)})
+	_ = discountRepo.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	_ = productRepo.Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 79000)})
+	_ = discountRepo.ReplaceAll(nil)
+
+	publisher := &flakyPublisher{failures: 1}
+	dispatcher := events.NewDispatcher(outbox, publisher)
+
+	published, err := dispatcher.Dispatch(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 0, published)
+
+	published, err = dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, published)
+	assert.Equal(t, []catalog.EventType{
+		catalog.ProductCreatedEvent,
+		catalog.DiscountAppliedEvent,
+		catalog.PriceChangedEvent,
+		catalog.DiscountExpiredEvent,
+	}, eventTypes(publisher.Events()))
+
+	changed := publisher.Events()[2]
+	assert.Equal(t, uint64(3), changed.ID)
+	assert.Equal(t, catalog.SKU("000001"), changed.SKU)
+	assert.Equal(t, catalog.Price(79000), *changed.Price)
+	assert.Equal(t, catalog.Price(89000), *changed.PreviousPrice)
+
+	pending, _ := outbox.Pending(ctx, 10)
+	assert.Empty(t, pending)
+
+	published, err = dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+}
+
+func TestDispatcher_AcksPerBatch(t *testing.T) {
+	ctx := context.Background()
+	outbox := &recordingOutbox{Outbox: inmem.NewOutbox()}
+	productRepo := inmem.NewProductRepo(nil, inmem.WithOutbox(outbox.Outbox))
+	for _, sku := range []catalog.SKU{"000001", "000002", "000003"} {
+		_ = productRepo.Save([]*catalog.Product{catalog.NewProduct(sku, "Boots", "boots", 1000)})
+	}
+	publisher := &failingOncePublisher{failID: 3}
+	dispatcher := events.NewDispatcher(outbox, publisher)
+
+	published, err := dispatcher.Dispatch(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []uint64{2}, outbox.acks, "the published events are acknowledged once")
+
+	published, err = dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []uint64{2, 3}, outbox.acks)
+}
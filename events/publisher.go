@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+// MemoryPublisher keeps the published events, for tests and local runs.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []catalog.Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, e catalog.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *MemoryPublisher) Events() []catalog.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]catalog.Event(nil), p.events...)
+}
+
+// WriterPublisher writes the events to w as JSON lines.
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+func (p *WriterPublisher) Publish(ctx context.Context, e catalog.Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
+
+// Publishers publishes every event to all of its publishers, in order.
+type Publishers []Publisher
+
+func (ps Publishers) Publish(ctx context.Context, e catalog.Event) error {
+	for _, p := range ps {
+		if err := p.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package exporting
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	. "github.com/amelendres/go-catalog/catalog"
+)
+
+const merchantNamespace = "http://base.google.com/ns/1.0"
+
+type encoder interface {
+	begin() error
+	encode(p *DiscountedProduct) error
+	end() error
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) begin() error {
+	return e.w.Write([]string{"sku", "name", "category", "original_price", "final_price", "discount_percentage", "currency"})
+}
+
+func (e *csvEncoder) encode(p *DiscountedProduct) error {
+	discount := ""
+	if p.Price.DiscountPercentage != nil {
+		discount = strconv.Itoa(int(*p.Price.DiscountPercentage))
+	}
+	return e.w.Write([]string{
+		string(p.SKU),
+		p.Name,
+		string(p.Category),
+		strconv.Itoa(int(p.Price.Original)),
+		strconv.Itoa(int(p.Price.Final)),
+		discount,
+		string(p.Price.Currenty),
+	})
+}
+
+func (e *csvEncoder) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonLinesEncoder struct {
+	enc *json.Encoder
+}
+
+func newJSONLinesEncoder(w io.Writer) *jsonLinesEncoder {
+	return &jsonLinesEncoder{json.NewEncoder(w)}
+}
+
+func (e *jsonLinesEncoder) begin() error {
+	return nil
+}
+
+func (e *jsonLinesEncoder) encode(p *DiscountedProduct) error {
+	return e.enc.Encode(p)
+}
+
+func (e *jsonLinesEncoder) end() error {
+	return nil
+}
+
+type merchantItem struct {
+	XMLName      xml.Name `xml:"item"`
+	ID           string   `xml:"g:id"`
+	Title        string   `xml:"g:title"`
+	Description  string   `xml:"g:description"`
+	Link         string   `xml:"g:link,omitempty"`
+	Availability string   `xml:"g:availability"`
+	Price        string   `xml:"g:price"`
+	SalePrice    string   `xml:"g:sale_price,omitempty"`
+	ProductType  string   `xml:"g:product_type"`
+}
+
+func newMerchantItem(p *DiscountedProduct, linkBase string) merchantItem {
+	item := merchantItem{
+		ID:           string(p.SKU),
+		Title:        p.Name,
+		Description:  p.Name,
+		Availability: "in stock",
+		Price:        formatMerchantPrice(p.Price.Original, p.Price.Currenty),
+		ProductType:  string(p.Category),
+	}
+	if linkBase != "" {
+		item.Link = linkBase + "/products/" + string(p.SKU)
+	}
+	if p.Price.Final != p.Price.Original {
+		item.SalePrice = formatMerchantPrice(p.Price.Final, p.Price.Currenty)
+	}
+	return item
+}
+
+func (i merchantItem) fields() []string {
+	return []string{i.ID, i.Title, i.Description, i.Link, i.Availability, i.Price, i.SalePrice, i.ProductType}
+}
+
+// formatMerchantPrice renders a price in minor units as Google Merchant expects it, e.g. "890.00 EUR".
+func formatMerchantPrice(p Price, c Currency) string {
+	sign := ""
+	if p < 0 {
+		sign, p = "-", -p
+	}
+	return fmt.Sprintf("%s%d.%02d %s", sign, p/100, p%100, c)
+}
+
+type merchantXMLEncoder struct {
+	w        io.Writer
+	enc      *xml.Encoder
+	linkBase string
+}
+
+func newMerchantXMLEncoder(w io.Writer, linkBase string) *merchantXMLEncoder {
+	return &merchantXMLEncoder{w, xml.NewEncoder(w), linkBase}
+}
+
+func (e *merchantXMLEncoder) begin() error {
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+	tokens := []xml.Token{
+		xml.StartElement{Name: xml.Name{Local: "rss"}, Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns:g"}, Value: merchantNamespace},
+			{Name: xml.Name{Local: "version"}, Value: "2.0"},
+		}},
+		xml.StartElement{Name: xml.Name{Local: "channel"}},
+	}
+	for _, t := range tokens {
+		if err := e.enc.EncodeToken(t); err != nil {
+			return err
+		}
+	}
+	return e.enc.EncodeElement("Products", xml.StartElement{Name: xml.Name{Local: "title"}})
+}
+
+func (e *merchantXMLEncoder) encode(p *DiscountedProduct) error {
+	return e.enc.Encode(newMerchantItem(p, e.linkBase))
+}
+
+func (e *merchantXMLEncoder) end() error {
+	for _, name := range []string{"channel", "rss"} {
+		if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	if err := e.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+type merchantTSVEncoder struct {
+	w        *csv.Writer
+	linkBase string
+}
+
+func newMerchantTSVEncoder(w io.Writer, linkBase string) *merchantTSVEncoder {
+	tsv := csv.NewWriter(w)
+	tsv.Comma = '\t'
+	return &merchantTSVEncoder{tsv, linkBase}
+}
+
+func (e *merchantTSVEncoder) begin() error {
+	return e.w.Write([]string{"id", "title", "description", "link", "availability", "price", "sale_price", "product_type"})
+}
+
+func (e *merchantTSVEncoder) encode(p *DiscountedProduct) error {
+	return e.w.Write(newMerchantItem(p, e.linkBase).fields())
+}
+
+func (e *merchantTSVEncoder) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
@@ -0,0 +1,118 @@
+package exporting
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	. "github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+)
+
+type Format string
+
+const (
+	CSVFormat         = Format("csv")
+	JSONLinesFormat   = Format("jsonl")
+	MerchantXMLFormat = Format("merchant-xml")
+	MerchantTSVFormat = Format("merchant-tsv")
+
+	pageSize = 100
+)
+
+var ErrUnknownFormat = NewInvalidArgumentError("unknown export format")
+
+var Formats = []Format{CSVFormat, JSONLinesFormat, MerchantXMLFormat, MerchantTSVFormat}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case CSVFormat, JSONLinesFormat, MerchantXMLFormat, MerchantTSVFormat:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownFormat, s)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case CSVFormat:
+		return "text/csv"
+	case JSONLinesFormat:
+		return "application/x-ndjson"
+	case MerchantXMLFormat:
+		return "application/xml"
+	case MerchantTSVFormat:
+		return "text/tab-separated-values"
+	}
+	return "application/octet-stream"
+}
+
+func (f Format) FileName() string {
+	switch f {
+	case MerchantXMLFormat:
+		return "products.xml"
+	case MerchantTSVFormat:
+		return "products.tsv"
+	}
+	return "products." + string(f)
+}
+
+type Exporter interface {
+	Export(ctx context.Context, w io.Writer, f Format) error
+}
+
+type service struct {
+	productLister listing.ProductLister
+	linkBase      string
+}
+
+// NewExporter builds an Exporter streaming the discounted catalog page by page.
+// linkBase is used to build the product links required by Google Merchant feeds.
+func NewExporter(pl listing.ProductLister, linkBase string) Exporter {
+	return &service{pl, strings.TrimSuffix(linkBase, "/")}
+}
+
+func (s service) Export(ctx context.Context, w io.Writer, f Format) error {
+	enc, err := s.newEncoder(w, f)
+	if err != nil {
+		return err
+	}
+
+	if err := enc.begin(); err != nil {
+		return err
+	}
+	for offset := 0; ; {
+		pag, err := NewPagination(pageSize, offset)
+		if err != nil {
+			return err
+		}
+		page, err := s.productLister.List(ctx, NewSearchCriteria(pag, nil))
+		if err != nil {
+			return err
+		}
+		for _, p := range page.Items() {
+			if err := enc.encode(p); err != nil {
+				return err
+			}
+		}
+		offset += len(page.Items())
+		if len(page.Items()) == 0 || offset >= page.MetaData().Total {
+			break
+		}
+	}
+	return enc.end()
+}
+
+func (s service) newEncoder(w io.Writer, f Format) (encoder, error) {
+	switch f {
+	case CSVFormat:
+		return newCSVEncoder(w), nil
+	case JSONLinesFormat:
+		return newJSONLinesEncoder(w), nil
+	case MerchantXMLFormat:
+		return newMerchantXMLEncoder(w, s.linkBase), nil
+	case MerchantTSVFormat:
+		return newMerchantTSVEncoder(w, s.linkBase), nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, f)
+}
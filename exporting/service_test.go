@@ -0,0 +1,140 @@
+package exporting_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/exporting"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/testing/stub"
+	"github.com/stretchr/testify/assert"
+)
+
+var givenCategoryDiscount = catalog.DiscountPercentage(30)
+
+func TestExporter_Export(t *testing.T) {
+	lister := newProductLister(250)
+
+	tests := map[string]struct {
+		format exporting.Format
+		count  func(t *testing.T, out []byte) int
+	}{
+		"CSV": {
+			format: exporting.CSVFormat,
+			count:  countCSVRows(','),
+		},
+		"JSON Lines": {
+			format: exporting.JSONLinesFormat,
+			count:  countJSONLines,
+		},
+		"Google Merchant XML": {
+			format: exporting.MerchantXMLFormat,
+			count:  countMerchantItems,
+		},
+		"Google Merchant TSV": {
+			format: exporting.MerchantTSVFormat,
+			count:  countCSVRows('\t'),
+		},
+	}
+
+	for name, tc := range tests {
+		var out bytes.Buffer
+		err := exporting.NewExporter(lister, "https://shop.example.com").Export(context.Background(), &out, tc.format)
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, 250, tc.count(t, out.Bytes()), name)
+	}
+}
+
+func TestExporter_Export_MerchantPrices(t *testing.T) {
+	var out bytes.Buffer
+	err := exporting.NewExporter(newProductLister(2), "https://shop.example.com/").Export(context.Background(), &out, exporting.MerchantTSVFormat)
+
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"id\ttitle\tdescription\tlink\tavailability\tprice\tsale_price\tproduct_type\n"+
+			"000000\tProduct 0\tProduct 0\thttps://shop.example.com/products/000000\tin stock\t100.00 EUR\t70.00 EUR\tboots\n"+
+			"000001\tProduct 1\tProduct 1\thttps://shop.example.com/products/000001\tin stock\t100.01 EUR\t\tsandals\n",
+		out.String(),
+	)
+}
+
+func TestExporter_Export_WithListerError(t *testing.T) {
+	discountRepoErr := errors.New("fails discount repository")
+	pricingCalculater := pricingacl.NewCalculater(stub.NewStubDiscountRepo(nil, discountRepoErr))
+	lister := listing.NewProductLister(inmem.NewProductRepo(newProducts(1)), pricingCalculater)
+
+	err := exporting.NewExporter(lister, "").Export(context.Background(), &bytes.Buffer{}, exporting.CSVFormat)
+
+	assert.ErrorIs(t, err, discountRepoErr)
+}
+
+func newProductLister(n int) listing.ProductLister {
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)})
+	return listing.NewProductLister(inmem.NewProductRepo(newProducts(n)), pricingacl.NewCalculater(discountRepo))
+}
+
+func newProducts(n int) []*catalog.Product {
+	categories := []catalog.Category{"boots", "sandals"}
+	var products []*catalog.Product
+	for i := 0; i < n; i++ {
+		products = append(products, catalog.NewProduct(
+			catalog.SKU(fmt.Sprintf("%06d", i)),
+			fmt.Sprintf("Product %d", i),
+			categories[i%len(categories)],
+			catalog.Price(10000+i),
+		))
+	}
+	return products
+}
+
+func countCSVRows(comma rune) func(t *testing.T, out []byte) int {
+	return func(t *testing.T, out []byte) int {
+		t.Helper()
+		r := csv.NewReader(bytes.NewReader(out))
+		r.Comma = comma
+		rows, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("fails reading export %v", err)
+		}
+		return len(rows) - 1
+	}
+}
+
+func countJSONLines(t *testing.T, out []byte) int {
+	t.Helper()
+	n := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		var p catalog.DiscountedProduct
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			t.Fatalf("fails decoding JSON line %v", err)
+		}
+		n++
+	}
+	return n
+}
+
+func countMerchantItems(t *testing.T, out []byte) int {
+	t.Helper()
+	var feed struct {
+		Items []struct {
+			ID string `xml:"id"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.NewDecoder(strings.NewReader(string(out))).Decode(&feed); err != nil {
+		t.Fatalf("fails decoding merchant feed %v", err)
+	}
+	return len(feed.Items)
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusUp   = Status("up")
+	StatusDown = Status("down")
+
+	defaultCheckTimeout = 2 * time.Second
+)
+
+// Pinger is implemented by the adapters able to report whether their backing
+// resource is reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type ComponentReport struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     Status                     `json:"status"`
+	Components map[string]ComponentReport `json:"components,omitempty"`
+}
+
+type component struct {
+	name   string
+	pinger Pinger
+}
+
+type Checker struct {
+	readiness  *Readiness
+	components []component
+	timeout    time.Duration
+}
+
+// NewChecker builds a Checker, a nil readiness means the service is always ready.
+func NewChecker(r *Readiness) *Checker {
+	return &Checker{readiness: r, timeout: defaultCheckTimeout}
+}
+
+func (c *Checker) Register(name string, p Pinger) {
+	c.components = append(c.components, component{name, p})
+	sort.Slice(c.components, func(i, j int) bool {
+		return c.components[i].name < c.components[j].name
+	})
+}
+
+// Live reports whether the process is able to serve at all, it doesn't check
+// any component so a dependency outage doesn't get the service restarted.
+func (c *Checker) Live(ctx context.Context) Report {
+	return Report{Status: StatusUp}
+}
+
+// Ready reports whether the service should receive traffic: it is not
+// shutting down and every registered component is up.
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Components: make(map[string]ComponentReport, len(c.components))}
+	if c.readiness != nil && !c.readiness.Ready() {
+		report.Status = StatusDown
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, comp := range c.components {
+		wg.Add(1)
+		go func(comp component) {
+			defer wg.Done()
+			cr := ComponentReport{Status: StatusUp}
+			if err := comp.pinger.Ping(ctx); err != nil {
+				cr = ComponentReport{Status: StatusDown, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[comp.name] = cr
+			if cr.Status == StatusDown {
+				report.Status = StatusDown
+			}
+		}(comp)
+	}
+	wg.Wait()
+
+	return report
+}
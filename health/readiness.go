@@ -0,0 +1,24 @@
+package health
+
+import "sync/atomic"
+
+// Readiness tells whether the service accepts traffic. It starts not ready.
+type Readiness struct {
+	ready int32
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	var v int32
+	if ready {
+		v = 1
+	}
+	atomic.StoreInt32(&r.ready, v)
+}
+
+func (r *Readiness) Ready() bool {
+	return atomic.LoadInt32(&r.ready) == 1
+}
@@ -0,0 +1,31 @@
+package history
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+type calculater struct {
+	next    catalog.Calculater
+	service *Service
+}
+
+// Calculater adds the lowest price of the last 30 days to the prices of next
+// that have a discount applied.
+func (s *Service) Calculater(next catalog.Calculater) catalog.Calculater {
+	return &calculater{next, s}
+}
+
+func (c *calculater) Calculate(ctx context.Context, p catalog.Product) (*catalog.DiscountedPrice, error) {
+	price, err := c.next.Calculate(ctx, p)
+	if err != nil || price.DiscountPercentage == nil {
+		return price, err
+	}
+	lowest, err := c.service.LowestPrice(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	price.Lowest30DaysPrice = &lowest
+	return price, nil
+}
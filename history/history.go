@@ -0,0 +1,70 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+const (
+	ProductDiscountType  = catalog.ProductDiscountType
+	CategoryDiscountType = catalog.CategoryDiscountType
+
+	// lowestPriceWindow is the period the lowest prior price is looked up in,
+	// as required by the EU Omnibus directive.
+	lowestPriceWindow = 30 * 24 * time.Hour
+)
+
+// PriceChange records the price a product had from At on.
+type PriceChange struct {
+	SKU   catalog.SKU   `json:"-"`
+	Price catalog.Price `json:"price"`
+	At    time.Time     `json:"at"`
+}
+
+// DiscountChange records the percentage of a product or category discount
+// from At on, a nil percentage means the discount was removed.
+type DiscountChange struct {
+	Type       string                      `json:"type"`
+	Target     string                      `json:"target"`
+	Percentage *catalog.DiscountPercentage `json:"percentage"`
+	At         time.Time                   `json:"at"`
+}
+
+// PriceHistory is the audit trail of everything that priced a product.
+type PriceHistory struct {
+	SKU       catalog.SKU      `json:"sku"`
+	Category  catalog.Category `json:"category"`
+	Prices    []PriceChange    `json:"prices"`
+	Discounts []DiscountChange `json:"discounts"`
+}
+
+// Repository keeps the changes in the order they were added.
+type Repository interface {
+	AddPriceChanges(ctx context.Context, changes []PriceChange) error
+	AddDiscountChanges(ctx context.Context, changes []DiscountChange) error
+	PriceChanges(ctx context.Context, sku catalog.SKU) ([]PriceChange, error)
+	DiscountChanges(ctx context.Context, sku catalog.SKU, category catalog.Category) ([]DiscountChange, error)
+	// LatestDiscounts returns the last change of every discount target.
+	LatestDiscounts(ctx context.Context) ([]DiscountChange, error)
+	// SupersededPrices counts the price changes followed by another one at or
+	// before t.
+	SupersededPrices(ctx context.Context, t time.Time) (int, error)
+}
+
+// discountChange records the discounts for everyone, as the lowest price of
+// the last 30 days is the one of the public price.
+func discountChange(d catalog.Discount, at time.Time) (DiscountChange, bool) {
+	if !d.Audience().IsZero() {
+		return DiscountChange{}, false
+	}
+	percentage := d.Percentage()
+	switch discount := d.(type) {
+	case *catalog.ProductDiscount:
+		return DiscountChange{ProductDiscountType, string(discount.SKU()), &percentage, at}, true
+	case *catalog.CategoryDiscount:
+		return DiscountChange{CategoryDiscountType, string(discount.Category()), &percentage, at}, true
+	}
+	return DiscountChange{}, false
+}
@@ -0,0 +1,153 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+)
+
+// Service records the price and discount changes written to the catalog
+// stores and reads them back.
+type Service struct {
+	repository Repository
+	now        func() time.Time
+}
+
+type Option func(s *Service)
+
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
+}
+
+func NewService(r Repository, opts ...Option) *Service {
+	s := &Service{repository: r, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// PriceHistory returns the audit trail of the listed product with the given SKU.
+func (s *Service) PriceHistory(ctx context.Context, pl listing.ProductLister, sku catalog.SKU) (*PriceHistory, error) {
+	p, err := listing.GetProduct(ctx, pl, sku)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := s.repository.PriceChanges(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+	discounts, err := s.repository.DiscountChanges(ctx, sku, p.Category)
+	if err != nil {
+		return nil, err
+	}
+	if prices == nil {
+		prices = []PriceChange{}
+	}
+	if discounts == nil {
+		discounts = []DiscountChange{}
+	}
+	return &PriceHistory{sku, p.Category, prices, discounts}, nil
+}
+
+// LowestPrice returns the lowest price p had over the last 30 days, discounts
+// excluded. Products without recorded changes only had their current price.
+func (s *Service) LowestPrice(ctx context.Context, p catalog.Product) (catalog.Price, error) {
+	return s.LowestPriceAt(ctx, p, s.now())
+}
+
+// LowestPriceAt returns the lowest price p had over the 30 days before at,
+// taking p as its price from then on.
+func (s *Service) LowestPriceAt(ctx context.Context, p catalog.Product, at time.Time) (catalog.Price, error) {
+	changes, err := s.repository.PriceChanges(ctx, p.SKU)
+	if err != nil {
+		return 0, err
+	}
+	since := at.Add(-lowestPriceWindow)
+	lowest := p.Price
+	for i, c := range changes {
+		if c.At.After(at) {
+			break
+		}
+		// a price counts while it was in effect within the window, that is
+		// when the next change came after the window started.
+		if i+1 < len(changes) && !changes[i+1].At.After(since) {
+			continue
+		}
+		if c.Price < lowest {
+			lowest = c.Price
+		}
+	}
+	return lowest, nil
+}
+
+// Version counts the recorded prices that left the lowest price window, so
+// the ETags derived from it change as the window slides.
+func (s *Service) Version() uint64 {
+	n, err := s.repository.SupersededPrices(context.Background(), s.now().Add(-lowestPriceWindow))
+	if err != nil {
+		return 0
+	}
+	return uint64(n)
+}
+
+func (s *Service) recordPrices(ctx context.Context, products []*catalog.Product) error {
+	at := s.now()
+	var changes []PriceChange
+	for _, p := range products {
+		previous, err := s.repository.PriceChanges(ctx, p.SKU)
+		if err != nil {
+			return err
+		}
+		if len(previous) > 0 && previous[len(previous)-1].Price == p.Price {
+			continue
+		}
+		changes = append(changes, PriceChange{p.SKU, p.Price, at})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.repository.AddPriceChanges(ctx, changes)
+}
+
+func (s *Service) recordDiscounts(ctx context.Context, discounts []catalog.Discount, replace bool) error {
+	at := s.now()
+	latest, err := s.repository.LatestDiscounts(ctx)
+	if err != nil {
+		return err
+	}
+	current := make(map[[2]string]*catalog.DiscountPercentage)
+	for _, c := range latest {
+		current[[2]string{c.Type, c.Target}] = c.Percentage
+	}
+
+	var changes []DiscountChange
+	written := make(map[[2]string]bool)
+	for _, d := range discounts {
+		c, ok := discountChange(d, at)
+		if !ok {
+			continue
+		}
+		key := [2]string{c.Type, c.Target}
+		written[key] = true
+		if previous := current[key]; previous != nil && *previous == *c.Percentage {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	if replace {
+		for _, c := range latest {
+			if c.Percentage != nil && !written[[2]string{c.Type, c.Target}] {
+				changes = append(changes, DiscountChange{c.Type, c.Target, nil, at})
+			}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.repository.AddDiscountChanges(ctx, changes)
+}
@@ -0,0 +1,113 @@
+package history_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/history"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func percentage(p catalog.DiscountPercentage) *catalog.DiscountPercentage {
+	return &p
+}
+
+func price(p catalog.Price) *catalog.Price {
+	return &p
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	day := 24 * time.Hour
+	start := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	service := history.NewService(inmem.NewHistoryRepo(), history.WithClock(func() time.Time { return now }))
+
+	productRepo := inmem.NewProductRepo(nil)
+	discountRepo := inmem.NewDiscountRepo(nil)
+	products, discounts := service.ProductStore(productRepo), service.DiscountStore(discountRepo)
+	calculater := service.Calculater(pricingacl.NewCalculater(discountRepo))
+	productLister := listing.NewProductLister(productRepo, calculater)
+
+	boots := func(p catalog.Price) []*catalog.Product {
+		return []*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", p)}
+	}
+
+	_ = products.Save(boots(90000))
+	now = start.Add(10 * day)
+	_ = products.Save(boots(80000))
+	_ = products.Save(boots(80000))
+	now = start.Add(20 * day)
+	_ = products.Save(boots(100000))
+	// discounts limited to a pricing context are not part of the history.
+	_ = discounts.Save([]catalog.Discount{
+		catalog.NewCategoryDiscount("boots", 30),
+		catalog.NewProductDiscount("000002", 10),
+		catalog.NewCategoryDiscount("boots", 50, catalog.ForAudience(catalog.PricingContext{Segment: "b2b"})),
+	})
+	now = start.Add(25 * day)
+	_ = discounts.ReplaceAll([]catalog.Discount{catalog.NewCategoryDiscount("boots", 20)})
+
+	got, err := service.PriceHistory(ctx, productLister, "000001")
+	assert.NoError(t, err)
+	assert.Equal(t, &history.PriceHistory{
+		SKU:      "000001",
+		Category: "boots",
+		Prices: []history.PriceChange{
+			{SKU: "000001", Price: 90000, At: start},
+			{SKU: "000001", Price: 80000, At: start.Add(10 * day)},
+			{SKU: "000001", Price: 100000, At: start.Add(20 * day)},
+		},
+		Discounts: []history.DiscountChange{
+			{Type: "category", Target: "boots", Percentage: percentage(30), At: start.Add(20 * day)},
+			{Type: "category", Target: "boots", Percentage: percentage(20), At: start.Add(25 * day)},
+		},
+	}, got)
+
+	removed, _ := service.PriceHistory(ctx, productLister, "000001")
+	assert.Len(t, removed.Discounts, 2, "removed discounts of other products are not listed")
+
+	_, err = service.PriceHistory(ctx, productLister, "999999")
+	assert.ErrorIs(t, err, catalog.ErrNotFound)
+
+	tests := map[string]struct {
+		now         time.Time
+		wantLowest  catalog.Price
+		wantVersion uint64
+	}{
+		"Lowest price within the window": {
+			now:         start.Add(30 * day),
+			wantLowest:  80000,
+			wantVersion: 0,
+		},
+		"Price in effect when the window starts": {
+			now:         start.Add(39 * day),
+			wantLowest:  80000,
+			wantVersion: 0,
+		},
+		"Older prices are out of the window": {
+			now:         start.Add(50 * day),
+			wantLowest:  100000,
+			wantVersion: 2,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			now = tt.now
+			got, err := calculater.Calculate(ctx, *boots(100000)[0])
+			assert.NoError(t, err)
+			assert.Equal(t, catalog.Price(80000), got.Final)
+			assert.Equal(t, price(tt.wantLowest), got.Lowest30DaysPrice)
+			assert.Equal(t, tt.wantVersion, service.Version(), "the version changes as prices leave the window")
+		})
+	}
+
+	hat := catalog.NewProduct("000006", "AA hat", "hats", 72000)
+	got2, _ := calculater.Calculate(ctx, *hat)
+	assert.Nil(t, got2.Lowest30DaysPrice, "only discounted prices have a lowest price")
+}
@@ -0,0 +1,56 @@
+package history
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+type productStore struct {
+	next    catalog.ProductStore
+	service *Service
+}
+
+// ProductStore records the price changes of the products written to next.
+func (s *Service) ProductStore(next catalog.ProductStore) catalog.ProductStore {
+	return &productStore{next, s}
+}
+
+func (s *productStore) Save(products []*catalog.Product) error {
+	if err := s.next.Save(products); err != nil {
+		return err
+	}
+	return s.service.recordPrices(context.Background(), products)
+}
+
+func (s *productStore) ReplaceAll(products []*catalog.Product) error {
+	if err := s.next.ReplaceAll(products); err != nil {
+		return err
+	}
+	return s.service.recordPrices(context.Background(), products)
+}
+
+type discountStore struct {
+	next    catalog.DiscountStore
+	service *Service
+}
+
+// DiscountStore records the discounts written to next, and the ones removed
+// when they are all replaced.
+func (s *Service) DiscountStore(next catalog.DiscountStore) catalog.DiscountStore {
+	return &discountStore{next, s}
+}
+
+func (s *discountStore) Save(discounts []catalog.Discount) error {
+	if err := s.next.Save(discounts); err != nil {
+		return err
+	}
+	return s.service.recordDiscounts(context.Background(), discounts, false)
+}
+
+func (s *discountStore) ReplaceAll(discounts []catalog.Discount) error {
+	if err := s.next.ReplaceAll(discounts); err != nil {
+		return err
+	}
+	return s.service.recordDiscounts(context.Background(), discounts, true)
+}
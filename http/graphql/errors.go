@@ -0,0 +1,39 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/logging"
+	"go.uber.org/zap"
+)
+
+// queryError exposes the catalog error code as a GraphQL error extension.
+type queryError struct {
+	code    catalog.ErrorCode
+	message string
+}
+
+func (e *queryError) Error() string {
+	return e.message
+}
+
+func (e *queryError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// toQueryError hides the details of internal and unavailable errors, which
+// are logged instead.
+func toQueryError(ctx context.Context, err error) error {
+	code := catalog.ErrorCodeOf(err)
+	message := err.Error()
+	switch code {
+	case catalog.InternalError:
+		logging.FromContext(ctx).Error("query failed", zap.Error(err))
+		message = "internal error"
+	case catalog.UnavailableError:
+		logging.FromContext(ctx).Error("query failed", zap.Error(err))
+		message = "service unavailable"
+	}
+	return &queryError{code, message}
+}
@@ -0,0 +1,81 @@
+package graphql
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	jsonContentType = "application/json"
+
+	defaultLimit = 5
+	maxLimit     = 100
+	maxDepth     = 6
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Handler serves GraphQL queries over the catalog. Prices are computed by the
+// ProductLister once per distinct search of a request, never per field.
+type Handler struct {
+	productLister listing.ProductLister
+	productRepo   catalog.ProductRepository
+	discountRepo  catalog.DiscountRepository
+	defaultLimit  int
+	maxLimit      int
+	schema        *graphqlgo.Schema
+}
+
+type Option func(h *Handler)
+
+func WithPageSize(defaultLimit, maxLimit int) Option {
+	return func(h *Handler) {
+		h.defaultLimit = defaultLimit
+		h.maxLimit = maxLimit
+	}
+}
+
+func NewHandler(pl listing.ProductLister, pr catalog.ProductRepository, dr catalog.DiscountRepository, opts ...Option) *Handler {
+	h := &Handler{productLister: pl, productRepo: pr, discountRepo: dr, defaultLimit: defaultLimit, maxLimit: maxLimit}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.schema = graphqlgo.MustParseSchema(schema, &queryResolver{h}, graphqlgo.MaxDepth(maxDepth))
+	return h
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type errorResponse struct {
+	Errors []errorMessage `json:"errors"`
+}
+
+type errorMessage struct {
+	Message string `json:"message"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("content-type", jsonContentType)
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(errorResponse{[]errorMessage{{"invalid GraphQL request body"}}})
+		return
+	}
+
+	ctx := withLoader(r.Context(), newLoader(h.productLister))
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(resp)
+}
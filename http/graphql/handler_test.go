@@ -0,0 +1,125 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/graphql"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	givenProducts = []*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+		catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000),
+		catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+	}
+	givenDiscounts = []catalog.Discount{
+		catalog.NewCategoryDiscount("boots", 45, catalog.ForAudience(catalog.PricingContext{Segment: "b2b"})),
+		catalog.NewCategoryDiscount("boots", 30),
+		catalog.NewProductDiscount("000003", 15),
+	}
+)
+
+type countingCalculater struct {
+	catalog.Calculater
+	calls int32
+}
+
+func (c *countingCalculater) Calculate(ctx context.Context, p catalog.Product) (*catalog.DiscountedPrice, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return c.Calculater.Calculate(ctx, p)
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := map[string]struct {
+		query     string
+		wantBody  string
+		wantCalls int32
+	}{
+		"Products with nested prices": {
+			query:     `{ products(limit: 2) { meta { total limit offset } items { sku price { original final discountPercentage currency } } } }`,
+			wantBody:  `{"data":{"products":{"meta":{"total":3,"limit":2,"offset":0},"items":[{"sku":"000001","price":{"original":89000,"final":62300,"discountPercentage":30,"currency":"EUR"}},{"sku":"000003","price":{"original":71000,"final":49700,"discountPercentage":30,"currency":"EUR"}}]}}}`,
+			wantCalls: 2,
+		},
+		"Filtered by category and price": {
+			query:     `{ products(category: "boots", priceLessThan: 80000) { items { sku name category { name } } } }`,
+			wantBody:  `{"data":{"products":{"items":[{"sku":"000003","name":"Ashlington leather ankle boots","category":{"name":"boots"}}]}}}`,
+			wantCalls: 1,
+		},
+		"Aliases of the same search are priced once": {
+			query:     `{ a: products { items { price { final } } } b: products { items { sku price { original } } } }`,
+			wantBody:  `{"data":{"a":{"items":[{"price":{"final":62300}},{"price":{"final":49700}},{"price":{"final":79500}}]},"b":{"items":[{"sku":"000001","price":{"original":89000}},{"sku":"000003","price":{"original":71000}},{"sku":"000004","price":{"original":79500}}]}}}`,
+			wantCalls: 3,
+		},
+		"Product by sku": {
+			query:     `{ product(sku: "000004") { name price { final discountPercentage } } }`,
+			wantBody:  `{"data":{"product":{"name":"Naima embellished suede sandals","price":{"final":79500,"discountPercentage":null}}}}`,
+			wantCalls: 1,
+		},
+		"Unknown product": {
+			query:    `{ product(sku: "999999") { name } }`,
+			wantBody: `{"data":{"product":null}}`,
+		},
+		"Categories with their discount and products": {
+			query:     `{ categories { name discount { type target percentage } products(limit: 1) { items { sku } } } }`,
+			wantBody:  `{"data":{"categories":[{"name":"boots","discount":{"type":"category","target":"boots","percentage":30},"products":{"items":[{"sku":"000001"}]}},{"name":"sandals","discount":null,"products":{"items":[{"sku":"000004"}]}}]}}`,
+			wantCalls: 2,
+		},
+		"Discounts of a product": {
+			query:    `{ discounts(sku: "000003") { type target percentage } }`,
+			wantBody: `{"data":{"discounts":[{"type":"product","target":"000003","percentage":15}]}}`,
+		},
+		"Discounts of a category": {
+			query:    `{ discounts(category: "boots") { percentage audience { segment channel } } }`,
+			wantBody: `{"data":{"discounts":[{"percentage":45,"audience":{"segment":"b2b","channel":null}},{"percentage":30,"audience":null}]}}`,
+		},
+		"Invalid pagination": {
+			query:    `{ products(offset: -1) { items { sku } } }`,
+			wantBody: `{"errors":[{"message":"offset must not be negative, got -1","path":["products"],"extensions":{"code":"invalid_argument"}}],"data":null}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			discountRepo := inmem.NewDiscountRepo(givenDiscounts)
+			productRepo := inmem.NewProductRepo(givenProducts)
+			calculater := &countingCalculater{Calculater: pricingacl.NewCalculater(discountRepo)}
+			handler := graphql.NewHandler(listing.NewProductLister(productRepo, calculater), productRepo, discountRepo)
+
+			body := `{"query":` + quote(tt.query) + `}`
+			request := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+			response := httptest.NewRecorder()
+
+			handler.ServeHTTP(response, request)
+
+			assert.Equal(t, http.StatusOK, response.Code)
+			assert.JSONEq(t, tt.wantBody, response.Body.String())
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt32(&calculater.calls))
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_InvalidBody(t *testing.T) {
+	productRepo := inmem.NewProductRepo(givenProducts)
+	discountRepo := inmem.NewDiscountRepo(givenDiscounts)
+	handler := graphql.NewHandler(listing.NewProductLister(productRepo, pricingacl.NewCalculater(discountRepo)), productRepo, discountRepo)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("query")))
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.JSONEq(t, `{"errors":[{"message":"invalid GraphQL request body"}]}`, response.Body.String())
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+)
+
+type loaderKey struct{}
+
+// loader batches the product listings of a single request: every distinct
+// search is listed, and therefore priced, once however many fields or
+// aliases ask for it.
+type loader struct {
+	productLister listing.ProductLister
+	mu            sync.Mutex
+	pages         map[string]*pageCall
+}
+
+type pageCall struct {
+	done chan struct{}
+	page *catalog.PaginatedDiscountedProducts
+	err  error
+}
+
+func newLoader(pl listing.ProductLister) *loader {
+	return &loader{productLister: pl, pages: make(map[string]*pageCall)}
+}
+
+func withLoader(ctx context.Context, l *loader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func loaderFrom(ctx context.Context, pl listing.ProductLister) *loader {
+	if l, ok := ctx.Value(loaderKey{}).(*loader); ok {
+		return l
+	}
+	return newLoader(pl)
+}
+
+func (l *loader) products(ctx context.Context, search catalog.SearchCriteria) (*catalog.PaginatedDiscountedProducts, error) {
+	key := fmt.Sprintf("%#v %#v", *search.Pagination(), search.Filters())
+
+	l.mu.Lock()
+	call, ok := l.pages[key]
+	if !ok {
+		call = &pageCall{done: make(chan struct{})}
+		l.pages[key] = call
+	}
+	l.mu.Unlock()
+
+	if ok {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return call.page, call.err
+	}
+
+	call.page, call.err = l.productLister.List(ctx, search)
+	close(call.done)
+	return call.page, call.err
+}
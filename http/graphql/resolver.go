@@ -0,0 +1,304 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+const categoriesPageSize = 100
+
+type queryResolver struct {
+	h *Handler
+}
+
+type pageArgs struct {
+	Limit         *int32
+	Offset        *int32
+	PriceLessThan *int32
+}
+
+type productsArgs struct {
+	Limit         *int32
+	Offset        *int32
+	Category      *string
+	PriceLessThan *int32
+}
+
+func (r *queryResolver) Products(ctx context.Context, args productsArgs) (*pageResolver, error) {
+	var filters []catalog.Filter
+	if args.Category != nil {
+		filters = append(filters, catalog.NewCategoryFilter(catalog.Category(*args.Category)))
+	}
+	return r.h.products(ctx, pageArgs{args.Limit, args.Offset, args.PriceLessThan}, filters)
+}
+
+func (r *queryResolver) Product(ctx context.Context, args struct{ SKU string }) (*productResolver, error) {
+	pag, err := catalog.NewPagination(1, 0)
+	if err != nil {
+		return nil, err
+	}
+	search := catalog.NewSearchCriteria(pag, []catalog.Filter{catalog.NewSKUFilter(catalog.SKU(args.SKU))})
+	page, err := loaderFrom(ctx, r.h.productLister).products(ctx, search)
+	if err != nil {
+		return nil, toQueryError(ctx, err)
+	}
+	if len(page.Items()) == 0 {
+		return nil, nil
+	}
+	return &productResolver{r.h, page.Items()[0]}, nil
+}
+
+// Categories returns the categories of the catalog products, sorted by name.
+func (r *queryResolver) Categories(ctx context.Context) ([]*categoryResolver, error) {
+	seen := make(map[catalog.Category]bool)
+	for offset := 0; ; offset += categoriesPageSize {
+		pag, err := catalog.NewPagination(categoriesPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		page, err := r.h.productRepo.List(ctx, catalog.NewSearchCriteria(pag, nil))
+		if err != nil {
+			return nil, toQueryError(ctx, err)
+		}
+		for _, p := range page.Items() {
+			seen[p.Category] = true
+		}
+		if len(page.Items()) < categoriesPageSize {
+			break
+		}
+	}
+
+	var names []string
+	for c := range seen {
+		names = append(names, string(c))
+	}
+	sort.Strings(names)
+	categories := make([]*categoryResolver, 0, len(names))
+	for _, name := range names {
+		categories = append(categories, &categoryResolver{r.h, catalog.Category(name)})
+	}
+	return categories, nil
+}
+
+func (r *queryResolver) Discounts(ctx context.Context, args struct {
+	Category *string
+	SKU      *string
+}) ([]*discountResolver, error) {
+	var filters []catalog.Filter
+	if args.Category != nil {
+		filters = append(filters, catalog.NewCategoryFilter(catalog.Category(*args.Category)))
+	}
+	if args.SKU != nil {
+		filters = append(filters, catalog.NewSKUFilter(catalog.SKU(*args.SKU)))
+	}
+	if len(filters) == 0 {
+		return nil, toQueryError(ctx, catalog.NewInvalidArgumentError("discounts needs a category or a sku"))
+	}
+
+	discounts, err := r.h.discountRepo.Find(ctx, catalog.NewSearchCriteria(nil, filters))
+	if err != nil {
+		return nil, toQueryError(ctx, err)
+	}
+	resolvers := make([]*discountResolver, 0, len(discounts))
+	for _, d := range discounts {
+		resolvers = append(resolvers, &discountResolver{d})
+	}
+	return resolvers, nil
+}
+
+func (h *Handler) products(ctx context.Context, args pageArgs, filters []catalog.Filter) (*pageResolver, error) {
+	limit := h.defaultLimit
+	if args.Limit != nil {
+		limit = int(*args.Limit)
+	}
+	if limit > h.maxLimit {
+		limit = h.maxLimit
+	}
+	offset := 0
+	if args.Offset != nil {
+		offset = int(*args.Offset)
+	}
+	pag, err := catalog.NewPagination(limit, offset)
+	if err != nil {
+		return nil, toQueryError(ctx, err)
+	}
+	if args.PriceLessThan != nil {
+		filters = append(filters, catalog.NewPriceLessThanFilter(catalog.Price(*args.PriceLessThan)))
+	}
+
+	page, err := loaderFrom(ctx, h.productLister).products(ctx, catalog.NewSearchCriteria(pag, filters))
+	if err != nil {
+		return nil, toQueryError(ctx, err)
+	}
+	return &pageResolver{h, page}, nil
+}
+
+type pageResolver struct {
+	h    *Handler
+	page *catalog.PaginatedDiscountedProducts
+}
+
+func (r *pageResolver) Meta() *metaResolver {
+	return &metaResolver{r.page.MetaData()}
+}
+
+func (r *pageResolver) Items() []*productResolver {
+	items := make([]*productResolver, 0, len(r.page.Items()))
+	for _, p := range r.page.Items() {
+		items = append(items, &productResolver{r.h, p})
+	}
+	return items
+}
+
+type metaResolver struct {
+	meta catalog.PaginationMeta
+}
+
+func (r *metaResolver) Total() int32 {
+	return int32(r.meta.Total)
+}
+
+func (r *metaResolver) Limit() int32 {
+	return int32(r.meta.Limit)
+}
+
+func (r *metaResolver) Offset() int32 {
+	return int32(r.meta.Offset)
+}
+
+type productResolver struct {
+	h       *Handler
+	product *catalog.DiscountedProduct
+}
+
+func (r *productResolver) SKU() string {
+	return string(r.product.SKU)
+}
+
+func (r *productResolver) Name() string {
+	return r.product.Name
+}
+
+func (r *productResolver) Category() *categoryResolver {
+	return &categoryResolver{r.h, r.product.Category}
+}
+
+func (r *productResolver) Price() *priceResolver {
+	return &priceResolver{r.product.Price}
+}
+
+type priceResolver struct {
+	price catalog.DiscountedPrice
+}
+
+func (r *priceResolver) Original() int32 {
+	return int32(r.price.Original)
+}
+
+func (r *priceResolver) Final() int32 {
+	return int32(r.price.Final)
+}
+
+func (r *priceResolver) DiscountPercentage() *int32 {
+	if r.price.DiscountPercentage == nil {
+		return nil
+	}
+	dp := int32(*r.price.DiscountPercentage)
+	return &dp
+}
+
+func (r *priceResolver) Currency() string {
+	return string(r.price.Currenty)
+}
+
+func (r *priceResolver) Lowest30DaysPrice() *int32 {
+	if r.price.Lowest30DaysPrice == nil {
+		return nil
+	}
+	lowest := int32(*r.price.Lowest30DaysPrice)
+	return &lowest
+}
+
+type categoryResolver struct {
+	h        *Handler
+	category catalog.Category
+}
+
+func (r *categoryResolver) Name() string {
+	return string(r.category)
+}
+
+func (r *categoryResolver) Products(ctx context.Context, args pageArgs) (*pageResolver, error) {
+	return r.h.products(ctx, args, []catalog.Filter{catalog.NewCategoryFilter(r.category)})
+}
+
+func (r *categoryResolver) Discount(ctx context.Context) (*discountResolver, error) {
+	search := catalog.NewSearchCriteria(nil, []catalog.Filter{catalog.NewCategoryFilter(r.category)})
+	discounts, err := r.h.discountRepo.Find(ctx, search)
+	if err != nil {
+		return nil, toQueryError(ctx, err)
+	}
+	for _, d := range discounts {
+		if _, ok := d.(*catalog.CategoryDiscount); ok && d.Audience().IsZero() {
+			return &discountResolver{d}, nil
+		}
+	}
+	return nil, nil
+}
+
+type discountResolver struct {
+	discount catalog.Discount
+}
+
+func (r *discountResolver) Type() string {
+	if _, ok := r.discount.(*catalog.ProductDiscount); ok {
+		return "product"
+	}
+	return "category"
+}
+
+func (r *discountResolver) Target() string {
+	switch d := r.discount.(type) {
+	case *catalog.ProductDiscount:
+		return string(d.SKU())
+	case *catalog.CategoryDiscount:
+		return string(d.Category())
+	}
+	return ""
+}
+
+func (r *discountResolver) Percentage() int32 {
+	return int32(r.discount.Percentage())
+}
+
+func (r *discountResolver) Audience() *pricingContextResolver {
+	if r.discount.Audience().IsZero() {
+		return nil
+	}
+	return &pricingContextResolver{r.discount.Audience()}
+}
+
+type pricingContextResolver struct {
+	pc catalog.PricingContext
+}
+
+func (r *pricingContextResolver) Segment() *string {
+	return optional(r.pc.Segment)
+}
+
+func (r *pricingContextResolver) Channel() *string {
+	return optional(r.pc.Channel)
+}
+
+func (r *pricingContextResolver) Country() *string {
+	return optional(r.pc.Country)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/gorilla/mux"
+)
+
+const adminScheme = "adminToken"
+
+// WithAdminToken serves the admin routes, which redeem promo codes and manage
+// the webhook subscriptions, to the requests bearing token. They are not
+// served without one.
+func WithAdminToken(token string) Option {
+	return func(cs *CatalogServer) {
+		cs.adminToken = token
+	}
+}
+
+// handleAdmin registers the admin route h, documenting its bearer token, when
+// an admin token is set.
+func (cs *CatalogServer) handleAdmin(router *mux.Router, path, method string, h http.Handler, op *operation) {
+	if cs.adminToken == "" {
+		return
+	}
+	cs.spec.Components.SecuritySchemes = map[string]*securityScheme{
+		adminScheme: {Type: "http", Scheme: "bearer"},
+	}
+	op.Security = []map[string][]string{{adminScheme: {}}}
+	op.Responses["401"] = errorResponse("Missing or wrong admin token")
+	cs.handle(router, path, method, cs.authorize(h), op)
+}
+
+// authorize rejects the requests without the admin bearer token, comparing it
+// in constant time.
+func (cs *CatalogServer) authorize(h http.Handler) http.Handler {
+	token := []byte(cs.adminToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), token) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="catalog"`)
+			writeError(w, r, catalog.NewUnauthenticatedError("admin token required"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+// WithETags tags the cacheable routes with an ETag derived from the versions
+// of the catalog data, so If-None-Match requests are answered with 304 until
+// any of them changes.
+func WithETags(versions ...catalog.Versioner) Option {
+	return func(cs *CatalogServer) {
+		cs.versions = versions
+	}
+}
+
+// WithCacheControl sets the Cache-Control header of the successful responses
+// of the route.
+func WithCacheControl(route, value string) Option {
+	return func(cs *CatalogServer) {
+		cs.cacheControl[route] = value
+	}
+}
+
+// newEpoch identifies the process, since data versions start again on restart.
+func newEpoch() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (cs *CatalogServer) etag(r *http.Request, vary []string) string {
+	h := sha256.New()
+	fmt.Fprint(h, cs.epoch, r.Method, r.URL.RequestURI())
+	for _, name := range vary {
+		fmt.Fprint(h, ":", r.Header.Get(name))
+	}
+	for _, v := range cs.versions {
+		fmt.Fprint(h, ":", v.Version())
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// cacheable answers conditional requests of the route and sets its caching
+// headers on successful responses, which vary with the given request
+// headers.
+func (cs *CatalogServer) cacheable(route string, next http.HandlerFunc, vary ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Promo codes expire and run out of uses without the catalog changing.
+		if r.URL.Query().Get("promoCode") != "" {
+			w.Header().Set("cache-control", "no-store")
+			next(w, r)
+			return
+		}
+		headers := make(http.Header)
+		if cc := cs.cacheControl[route]; cc != "" {
+			headers.Set("cache-control", cc)
+		}
+		if len(vary) > 0 {
+			headers.Set("vary", strings.Join(vary, ", "))
+		}
+		if len(cs.versions) > 0 {
+			etag := cs.etag(r, vary)
+			headers.Set("etag", etag)
+			if matchesETag(r.Header.Get("if-none-match"), etag) {
+				copyHeaders(w.Header(), headers)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		next(&cachingWriter{ResponseWriter: w, headers: headers}, r)
+	}
+}
+
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func copyHeaders(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// cachingWriter adds the caching headers only when the response succeeds, so
+// errors are never cached.
+type cachingWriter struct {
+	http.ResponseWriter
+	headers     http.Header
+	wroteHeader bool
+}
+
+func (w *cachingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if status == http.StatusOK {
+			copyHeaders(w.Header(), w.headers)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cachingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cachingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
@@ -0,0 +1,76 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogServer_conditionalRequests(t *testing.T) {
+	productRepo := inmem.NewProductRepo(givenProducts)
+	discountRepo := inmem.NewDiscountRepo(nil)
+	productLister := listing.NewProductLister(productRepo, pricingacl.NewCalculater(discountRepo))
+	cs := rest.NewCatalogServer(
+		productLister,
+		rest.WithETags(productRepo, discountRepo),
+		rest.WithCacheControl("/products", "public, max-age=60"),
+	)
+
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		response := httptest.NewRecorder()
+		cs.ServeHTTP(response, req)
+		return response
+	}
+
+	first := get("/products", "")
+	etag := first.Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=60", first.Header().Get("Cache-Control"))
+	assert.Equal(t, "X-Pricing-Segment, X-Pricing-Channel, X-Pricing-Country", first.Header().Get("Vary"))
+
+	notModified := get("/products", etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	assert.Equal(t, etag, notModified.Header().Get("ETag"))
+
+	assert.Equal(t, http.StatusNotModified, get("/products", `"other", W/`+etag).Code)
+	assert.Equal(t, http.StatusOK, get("/products", `"other"`).Code)
+
+	otherPage := get("/products?offset=5", etag)
+	assert.Equal(t, http.StatusOK, otherPage.Code)
+	assert.NotEqual(t, etag, otherPage.Header().Get("ETag"))
+
+	b2bReq := httptest.NewRequest(http.MethodGet, "/products", nil)
+	b2bReq.Header.Set("If-None-Match", etag)
+	b2bReq.Header.Set("X-Pricing-Segment", "b2b")
+	b2b := httptest.NewRecorder()
+	cs.ServeHTTP(b2b, b2bReq)
+	assert.Equal(t, http.StatusOK, b2b.Code, "prices vary with the pricing context")
+	assert.NotEqual(t, etag, b2b.Header().Get("ETag"))
+
+	_ = discountRepo.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)})
+	afterDiscount := get("/products", etag)
+	assert.Equal(t, http.StatusOK, afterDiscount.Code)
+	assert.NotEqual(t, etag, afterDiscount.Header().Get("ETag"))
+	etag = afterDiscount.Header().Get("ETag")
+
+	_ = productRepo.Save([]*catalog.Product{catalog.NewProduct("000007", "Flip flops", "sandals", 1500)})
+	assert.Equal(t, http.StatusOK, get("/products", etag).Code)
+
+	invalid := get("/products?limit=Hi", "")
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Empty(t, invalid.Header().Get("ETag"))
+	assert.Empty(t, invalid.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/gorilla/mux"
+)
+
+// WithCoupons serves the validation of the promo codes at /coupons and, to
+// admins, their redemption. Promo codes are only created through imports.
+func WithCoupons(s *coupons.Service) Option {
+	return func(cs *CatalogServer) {
+		cs.coupons = s
+	}
+}
+
+func (cs *CatalogServer) validateCoupon(w http.ResponseWriter, r *http.Request) {
+	var p *catalog.Product
+	if sku := r.URL.Query().Get("sku"); sku != "" {
+		dp, err := listing.GetProduct(r.Context(), cs.productLister, catalog.SKU(sku))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		p = catalog.NewProduct(dp.SKU, dp.Name, dp.Category, dp.Price.Original)
+	}
+	v, err := cs.coupons.Validate(r.Context(), mux.Vars(r)["code"], p)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (cs *CatalogServer) redeemCoupon(w http.ResponseWriter, r *http.Request) {
+	c, err := cs.coupons.Redeem(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(c)
+}
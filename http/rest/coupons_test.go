@@ -0,0 +1,156 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCouponServer(opts ...rest.Option) *rest.CatalogServer {
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)})
+	s := coupons.NewService(inmem.NewCouponRepo(
+		coupons.Coupon{Code: "BOOTS40", Percentage: 40, MaxUses: 1, Eligibility: coupons.Eligibility{Categories: []catalog.Category{"boots"}, MinPrice: 80000}},
+		coupons.Coupon{Code: "SANDALS10", Percentage: 10, Eligibility: coupons.Eligibility{Categories: []catalog.Category{"sandals"}}},
+		coupons.Coupon{Code: "USEDUP", Percentage: 20, MaxUses: 1, Uses: 1},
+	))
+	engine := pricing.NewEngine(pricingacl.NewCouponRules(pricingacl.NewRules(discountRepo), s))
+	productLister := listing.NewProductLister(inmem.NewProductRepo(givenProducts), pricingacl.NewLocal(engine))
+	return rest.NewCatalogServer(productLister, append(opts, rest.WithCoupons(s))...)
+}
+
+func TestCatalogServer_listProducts_WithPromoCode(t *testing.T) {
+	cs := newCouponServer(rest.WithCacheControl("/products", "max-age=60"), rest.WithETags(inmem.NewProductRepo(givenProducts)))
+
+	tests := map[string]struct {
+		promoCode string
+		want      []catalog.Price
+		wantCode  []string
+	}{
+		"Without code":  {want: []catalog.Price{62300, 69300, 49700}, wantCode: []string{"", "", ""}},
+		"Eligible code": {promoCode: "boots40", want: []catalog.Price{53400, 59400, 49700}, wantCode: []string{"BOOTS40", "BOOTS40", ""}},
+		"Unknown code":  {promoCode: "SANDALS", want: []catalog.Price{62300, 69300, 49700}, wantCode: []string{"", "", ""}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			query := map[string]string{"category": "boots"}
+			if tc.promoCode != "" {
+				query["promoCode"] = tc.promoCode
+			}
+			response := httptest.NewRecorder()
+			cs.ServeHTTP(response, newListProductsRequest(t, query))
+
+			assert.Equal(t, http.StatusOK, response.Code)
+			if tc.promoCode != "" {
+				assert.Equal(t, "no-store", response.Header().Get("cache-control"))
+				assert.Empty(t, response.Header().Get("etag"))
+			} else {
+				assert.Equal(t, "max-age=60", response.Header().Get("cache-control"))
+			}
+			var got []catalog.Price
+			var gotCode []string
+			for _, p := range newPaginatedDiscountedProductsFromJSON(t, response.Body).Items() {
+				got = append(got, p.Price.Final)
+				gotCode = append(gotCode, p.Price.PromoCode)
+			}
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantCode, gotCode)
+		})
+	}
+}
+
+func TestCatalogServer_getProduct(t *testing.T) {
+	cs := newCouponServer()
+
+	tests := map[string]struct {
+		target string
+		status int
+		want   catalog.Price
+	}{
+		"Product":                 {target: "/products/000001", status: http.StatusOK, want: 62300},
+		"Product with promo code": {target: "/products/000001?promoCode=BOOTS40", status: http.StatusOK, want: 53400},
+		"Unknown product":         {target: "/products/999999", status: http.StatusNotFound},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			cs.ServeHTTP(response, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, tc.status, response.Code)
+			if tc.status != http.StatusOK {
+				return
+			}
+			var got catalog.DiscountedProduct
+			require.NoError(t, json.NewDecoder(response.Body).Decode(&got))
+			assert.Equal(t, catalog.SKU("000001"), got.SKU)
+			assert.Equal(t, tc.want, got.Price.Final)
+		})
+	}
+}
+
+func TestCatalogServer_coupons(t *testing.T) {
+	cs := newCouponServer(rest.WithAdminToken("s3cr3t"))
+	do := func(method, target, token string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		cs.ServeHTTP(response, req)
+		return response
+	}
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/coupons", "s3cr3t").Code, "coupons are only imported")
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/coupons/BOOTS40/redeem", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/coupons/BOOTS40/redeem", "guess").Code)
+	redeemed := do(http.MethodPost, "/coupons/boots40/redeem", "s3cr3t")
+	assert.Equal(t, http.StatusOK, redeemed.Code)
+	assert.Contains(t, redeemed.Body.String(), `"uses":1`)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/coupons/BOOTS40/redeem", "s3cr3t").Code, "the uses are bounded")
+	withoutToken := httptest.NewRecorder()
+	newCouponServer().ServeHTTP(withoutToken, httptest.NewRequest(http.MethodPost, "/coupons/SANDALS10/redeem", nil))
+	assert.Equal(t, http.StatusNotFound, withoutToken.Code, "admin routes are not served without a token")
+
+	tests := map[string]struct {
+		target  string
+		status  int
+		reasons []string
+	}{
+		"Applicable":         {target: "/coupons/SANDALS10/validate?sku=000004", status: http.StatusOK, reasons: []string{}},
+		"Without product":    {target: "/coupons/SANDALS10/validate", status: http.StatusOK, reasons: []string{}},
+		"Ineligible product": {target: "/coupons/BOOTS40/validate?sku=000004", status: http.StatusOK, reasons: []string{"used 1 of 1 times", "product 000004 of category sandals is not eligible", "price 79500 is below the minimum of 80000"}},
+		"Unknown product":    {target: "/coupons/BOOTS40/validate?sku=999999", status: http.StatusNotFound},
+		"Unknown code":       {target: "/coupons/SUMMER/validate", status: http.StatusNotFound},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			response := do(http.MethodGet, tc.target, "")
+
+			assert.Equal(t, tc.status, response.Code)
+			if tc.status != http.StatusOK {
+				return
+			}
+			var got coupons.Validation
+			require.NoError(t, json.NewDecoder(response.Body).Decode(&got))
+			assert.Equal(t, tc.reasons, got.Reasons)
+			assert.Equal(t, len(tc.reasons) == 0, got.Applicable)
+		})
+	}
+
+	response := do(http.MethodGet, "/coupons/USEDUP/validate", "")
+	assert.Contains(t, response.Body.String(), `"reasons":["used 1 of 1 times"]`)
+	response = do(http.MethodGet, "/products/000001?promoCode=USEDUP", "")
+	assert.NotContains(t, response.Body.String(), "USEDUP", "used up codes are not applied")
+}
@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/logging"
+	"go.uber.org/zap"
+)
+
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    catalog.ErrorCode `json:"code"`
+	Message string            `json:"message"`
+}
+
+var errorStatus = map[catalog.ErrorCode]int{
+	catalog.NotFoundError:        http.StatusNotFound,
+	catalog.InvalidArgumentError: http.StatusBadRequest,
+	catalog.UnavailableError:     http.StatusServiceUnavailable,
+	catalog.ConflictError:        http.StatusConflict,
+	catalog.UnauthenticatedError: http.StatusUnauthorized,
+	catalog.InternalError:        http.StatusInternalServerError,
+}
+
+func statusOf(code catalog.ErrorCode) int {
+	if status, ok := errorStatus[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// writeError maps err to its HTTP status and writes it as a JSON body. The
+// details of internal and unavailable errors, such as storage paths, are
+// logged but never returned to the client.
+func writeError(w http.ResponseWriter, r *http.Request, err error, fields ...zap.Field) {
+	code := catalog.ErrorCodeOf(err)
+	status := statusOf(code)
+
+	logger := logging.FromContext(r.Context())
+	fields = append(fields, zap.Error(err), zap.String("code", string(code)))
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		logger.Error("request failed", fields...)
+		switch code {
+		case catalog.InternalError:
+			message = "internal error"
+		case catalog.UnavailableError:
+			message = "service unavailable"
+		}
+	} else {
+		logger.Info("request rejected", fields...)
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorBody{errorDetail{code, message}})
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, catalog.NewNotFoundError("no route for %s %s", r.Method, r.URL.Path))
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	_ = json.NewEncoder(w).Encode(errorBody{errorDetail{"method_not_allowed", r.Method + " is not allowed on " + r.URL.Path}})
+}
@@ -0,0 +1,127 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+type ProductListerStub struct {
+	wantErr error
+}
+
+func (l ProductListerStub) List(ctx context.Context, search catalog.SearchCriteria) (*catalog.PaginatedDiscountedProducts, error) {
+	return nil, l.wantErr
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func TestCatalogServer_errors(t *testing.T) {
+	unavailable := catalog.NewUnavailableError(errors.New("connection refused"), "discounts database unavailable")
+
+	tests := map[string]struct {
+		listerErr   error
+		target      string
+		method      string
+		status      int
+		code        string
+		message     string
+		wantMessage bool
+	}{
+		"Invalid limit": {
+			target:      "/products?limit=Hi",
+			status:      400,
+			code:        "invalid_argument",
+			message:     `limit must be an integer, got "Hi"`,
+			wantMessage: true,
+		},
+		"Negative offset": {
+			target:      "/products?offset=-5",
+			status:      400,
+			code:        "invalid_argument",
+			message:     "offset must not be negative, got -5",
+			wantMessage: true,
+		},
+		"Invalid price filter": {
+			target:      "/products?priceLessThan=cheap",
+			status:      400,
+			code:        "invalid_argument",
+			message:     `priceLessThan must be an integer, got "cheap"`,
+			wantMessage: true,
+		},
+		"Unavailable repository": {
+			listerErr:   fmt.Errorf("listing products: %w", unavailable),
+			target:      "/products",
+			status:      503,
+			code:        "unavailable",
+			message:     "service unavailable",
+			wantMessage: true,
+		},
+		"Not found": {
+			listerErr: catalog.NewNotFoundError("category %q not found", "hats"),
+			target:    "/products",
+			status:    404,
+			code:      "not_found",
+		},
+		"Conflict": {
+			listerErr: catalog.NewConflictError("catalog is being replaced"),
+			target:    "/products",
+			status:    409,
+			code:      "conflict",
+		},
+		"Unclassified error": {
+			listerErr:   errors.New("nil pointer dereference"),
+			target:      "/products",
+			status:      500,
+			code:        "internal",
+			message:     "internal error",
+			wantMessage: true,
+		},
+		"Unknown route": {
+			target: "/carts",
+			status: 404,
+			code:   "not_found",
+		},
+		"Method not allowed": {
+			target: "/products",
+			method: http.MethodDelete,
+			status: 405,
+			code:   "method_not_allowed",
+		},
+	}
+
+	for name, tc := range tests {
+		catalogService := rest.NewCatalogServer(ProductListerStub{tc.listerErr})
+		method := tc.method
+		if method == "" {
+			method = http.MethodGet
+		}
+		response := httptest.NewRecorder()
+
+		catalogService.ServeHTTP(response, httptest.NewRequest(method, tc.target, nil))
+
+		assert.Equal(t, tc.status, response.Code, name)
+		assert.Equal(t, "application/json", response.Header().Get("content-type"), name)
+		var got errorResponse
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: fails json decoding error response %v", name, err)
+		}
+		assert.Equal(t, tc.code, got.Error.Code, name)
+		if tc.wantMessage {
+			assert.Equal(t, tc.message, got.Error.Message, name)
+		}
+	}
+}
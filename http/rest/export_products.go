@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/amelendres/go-catalog/exporting"
+	"github.com/amelendres/go-catalog/logging"
+	"go.uber.org/zap"
+)
+
+func (cs *CatalogServer) exportProducts(w http.ResponseWriter, r *http.Request) {
+	format := exporting.CSVFormat
+	if f := r.URL.Query().Get("format"); f != "" {
+		var err error
+		if format, err = exporting.ParseFormat(f); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	w.Header().Set("content-type", format.ContentType())
+	w.Header().Set("content-disposition", fmt.Sprintf("attachment; filename=%q", format.FileName()))
+
+	rw := newResponseWriter(w)
+	if err := cs.exporter.Export(r.Context(), rw, format); err != nil {
+		if rw.written {
+			logging.FromContext(r.Context()).Error("could not export products", zap.Error(err), zap.String("format", string(format)))
+			return
+		}
+		w.Header().Del("content-disposition")
+		writeError(w, r, err, zap.String("format", string(format)))
+	}
+}
@@ -0,0 +1,59 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogServer_exportProducts(t *testing.T) {
+	discounts := []catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)}
+	productLister := listing.NewProductLister(inmem.NewProductRepo(givenProducts), pricingacl.NewCalculater(inmem.NewDiscountRepo(discounts)))
+	catalogService := rest.NewCatalogServer(productLister)
+
+	tests := map[string]struct {
+		format      string
+		status      int
+		contentType string
+		lines       int
+	}{
+		"Default format": {
+			format:      "",
+			status:      200,
+			contentType: "text/csv",
+			lines:       len(givenProducts) + 1,
+		},
+		"JSON Lines": {
+			format:      "jsonl",
+			status:      200,
+			contentType: "application/x-ndjson",
+			lines:       len(givenProducts),
+		},
+		"Unknown format": {
+			format: "pdf",
+			status: 400,
+		},
+	}
+
+	for name, tc := range tests {
+		response := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/exports/products?format="+tc.format, nil)
+
+		catalogService.ServeHTTP(response, req)
+
+		assert.Equal(t, tc.status, response.Code, name)
+		if tc.status != 200 {
+			continue
+		}
+		assert.Equal(t, tc.contentType, response.Header().Get("content-type"), name)
+		assert.Equal(t, tc.lines, strings.Count(response.Body.String(), "\n"), name)
+	}
+}
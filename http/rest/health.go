@@ -0,0 +1,25 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/amelendres/go-catalog/health"
+)
+
+func (cs *CatalogServer) liveness(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, cs.health.Live(r.Context()))
+}
+
+func (cs *CatalogServer) readiness(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, cs.health.Ready(r.Context()))
+}
+
+func writeHealthReport(w http.ResponseWriter, report health.Report) {
+	w.Header().Set("content-type", jsonContentType)
+	w.Header().Set("cache-control", "no-store")
+	if report.Status != health.StatusUp {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,97 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amelendres/go-catalog/health"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+type PingerStub struct {
+	wantErr error
+}
+
+func (p PingerStub) Ping(ctx context.Context) error {
+	return p.wantErr
+}
+
+func TestCatalogServer_health(t *testing.T) {
+	productRepo := inmem.NewProductRepo(givenProducts)
+	discountRepo := inmem.NewDiscountRepo(nil)
+	productLister := listing.NewProductLister(productRepo, pricingacl.NewCalculater(discountRepo))
+
+	ready := health.NewReadiness()
+	ready.SetReady(true)
+	notReady := health.NewReadiness()
+
+	newChecker := func(r *health.Readiness, discounts health.Pinger) *health.Checker {
+		c := health.NewChecker(r)
+		c.Register("products", productRepo)
+		c.Register("discounts", discounts)
+		return c
+	}
+
+	tests := map[string]struct {
+		checker *health.Checker
+		path    string
+		status  int
+		want    health.Report
+	}{
+		"Live": {
+			checker: newChecker(notReady, PingerStub{errors.New("connection refused")}),
+			path:    "/healthz",
+			status:  200,
+			want:    health.Report{Status: health.StatusUp},
+		},
+		"Ready": {
+			checker: newChecker(ready, discountRepo),
+			path:    "/readyz",
+			status:  200,
+			want: health.Report{Status: health.StatusUp, Components: map[string]health.ComponentReport{
+				"products":  {Status: health.StatusUp},
+				"discounts": {Status: health.StatusUp},
+			}},
+		},
+		"Component down": {
+			checker: newChecker(ready, PingerStub{errors.New("connection refused")}),
+			path:    "/readyz",
+			status:  503,
+			want: health.Report{Status: health.StatusDown, Components: map[string]health.ComponentReport{
+				"products":  {Status: health.StatusUp},
+				"discounts": {Status: health.StatusDown, Error: "connection refused"},
+			}},
+		},
+		"Shutting down": {
+			checker: newChecker(notReady, discountRepo),
+			path:    "/readyz",
+			status:  503,
+			want: health.Report{Status: health.StatusDown, Components: map[string]health.ComponentReport{
+				"products":  {Status: health.StatusUp},
+				"discounts": {Status: health.StatusUp},
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		catalogService := rest.NewCatalogServer(productLister, rest.WithHealthChecker(tc.checker))
+		response := httptest.NewRecorder()
+
+		catalogService.ServeHTTP(response, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		assert.Equal(t, tc.status, response.Code, name)
+		var got health.Report
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: fails json decoding health report %v", name, err)
+		}
+		assert.Equal(t, tc.want, got, name)
+	}
+}
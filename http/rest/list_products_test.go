@@ -1,6 +1,7 @@
 package rest_test
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -12,7 +13,7 @@ import (
 	"github.com/amelendres/go-catalog/catalog"
 	"github.com/amelendres/go-catalog/http/rest"
 	"github.com/amelendres/go-catalog/listing"
-	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/pricingacl"
 	"github.com/amelendres/go-catalog/storage/inmem"
 	"github.com/amelendres/go-catalog/testing/mother"
 	"github.com/stretchr/testify/assert"
@@ -49,7 +50,7 @@ func TestCatalogServer_listProducts(t *testing.T) {
 
 	productRepo := inmem.NewProductRepo(givenProducts)
 	discountRepo := inmem.NewDiscountRepo(discounts)
-	pricingCalculater := pricing.NewCalculater(discountRepo)
+	pricingCalculater := pricingacl.NewCalculater(discountRepo)
 	productLister := listing.NewProductLister(productRepo, pricingCalculater)
 	catalogService := rest.NewCatalogServer(productLister)
 
@@ -57,19 +58,19 @@ func TestCatalogServer_listProducts(t *testing.T) {
 	secondPage, _ := catalog.NewPagination(5, 5)
 
 	searchWithoutFilter := catalog.NewSearchCriteria(firstPage, nil)
-	productsWithoutFilter, _ := productRepo.List(searchWithoutFilter)
+	productsWithoutFilter, _ := productRepo.List(context.Background(), searchWithoutFilter)
 	listWithoutFilter := mother.NewPaginatedDiscountedProducts(productsWithoutFilter.Items(), givenDiscountedPrices, *firstPage, 6)
 
 	searchSecondPage := catalog.NewSearchCriteria(secondPage, nil)
-	productsSecondPage, _ := productRepo.List(searchSecondPage)
+	productsSecondPage, _ := productRepo.List(context.Background(), searchSecondPage)
 	listSecondPage := mother.NewPaginatedDiscountedProducts(productsSecondPage.Items(), givenDiscountedPrices, *secondPage, 6)
 
 	searchBoots := catalog.NewSearchCriteria(firstPage, []catalog.Filter{catalog.NewCategoryFilter("boots")})
-	bootsProducts, _ := productRepo.List(searchBoots)
+	bootsProducts, _ := productRepo.List(context.Background(), searchBoots)
 	listBoots := mother.NewPaginatedDiscountedProducts(bootsProducts.Items(), givenDiscountedPrices, *firstPage, 3)
 
 	searchByPrice := catalog.NewSearchCriteria(firstPage, []catalog.Filter{catalog.NewPriceLessThanFilter(71000)})
-	priceProducts, _ := productRepo.List(searchByPrice)
+	priceProducts, _ := productRepo.List(context.Background(), searchByPrice)
 	listByPrice := mother.NewPaginatedDiscountedProducts(priceProducts.Items(), givenDiscountedPrices, *firstPage, 2)
 
 	listEmpty := mother.NewPaginatedDiscountedProducts(nil, nil, *firstPage, 0)
@@ -134,7 +135,7 @@ func TestCatalogServer_listProducts_WithInvalidRequest(t *testing.T) {
 
 	productRepo := inmem.NewProductRepo(givenProducts)
 	discountRepo := inmem.NewDiscountRepo(discounts)
-	pricingCalculater := pricing.NewCalculater(discountRepo)
+	pricingCalculater := pricingacl.NewCalculater(discountRepo)
 	productLister := listing.NewProductLister(productRepo, pricingCalculater)
 	catalogService := rest.NewCatalogServer(productLister)
 
@@ -166,6 +167,10 @@ func TestCatalogServer_listProducts_WithInvalidRequest(t *testing.T) {
 			search: invalidOffset,
 			status: 400,
 		},
+		"Invalid explain": {
+			search: map[string]string{"explain": "maybe"},
+			status: 400,
+		},
 	}
 
 	for name, tc := range tests {
@@ -175,6 +180,100 @@ func TestCatalogServer_listProducts_WithInvalidRequest(t *testing.T) {
 	}
 }
 
+func TestCatalogServer_listProducts_Explained(t *testing.T) {
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{
+		catalog.NewCategoryDiscount("boots", givenCategoryDiscount),
+		catalog.NewProductDiscount("000003", givenProductDiscount),
+	})
+	productLister := listing.NewProductLister(inmem.NewProductRepo(givenProducts), pricingacl.NewCalculater(discountRepo))
+	catalogService := rest.NewCatalogServer(productLister)
+
+	response := httptest.NewRecorder()
+	catalogService.ServeHTTP(response, newListProductsRequest(t, map[string]string{"category": "boots", "explain": "true"}))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	got := newPaginatedDiscountedProductsFromJSON(t, response.Body)
+	assert.Len(t, got.Items(), 3)
+	assert.Equal(t, &catalog.PriceExplanation{Candidates: []catalog.DiscountCandidate{
+		{Source: "category", Target: "boots", Percentage: givenCategoryDiscount, Applied: true, Reason: "highest percentage"},
+		{Source: "product", Target: "000003", Percentage: givenProductDiscount, Reason: "lower percentage than the applied rule"},
+	}}, got.Items()[2].Price.Explanation)
+
+	response = httptest.NewRecorder()
+	catalogService.ServeHTTP(response, newListProductsRequest(t, map[string]string{"category": "boots"}))
+
+	got = newPaginatedDiscountedProductsFromJSON(t, response.Body)
+	assert.Nil(t, got.Items()[2].Price.Explanation, "prices are explained on request")
+}
+
+func TestCatalogServer_listProducts_ForPricingContext(t *testing.T) {
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{
+		catalog.NewCategoryDiscount("boots", givenCategoryDiscount),
+		catalog.NewCategoryDiscount("boots", 40, catalog.ForAudience(catalog.PricingContext{Segment: "b2b"})),
+		catalog.NewProductDiscount("000003", 50, catalog.ForAudience(catalog.PricingContext{Channel: "mobile", Country: "ES"})),
+	})
+	productLister := listing.NewProductLister(inmem.NewProductRepo(givenProducts), pricingacl.NewCalculater(discountRepo))
+	catalogService := rest.NewCatalogServer(productLister)
+
+	tests := map[string]struct {
+		query   map[string]string
+		headers map[string]string
+		status  int
+		want    []catalog.Price
+	}{
+		"Everyone": {
+			status: http.StatusOK,
+			want:   []catalog.Price{62300, 69300, 49700},
+		},
+		"Segment header": {
+			headers: map[string]string{"X-Pricing-Segment": "b2b"},
+			status:  http.StatusOK,
+			want:    []catalog.Price{53400, 59400, 42600},
+		},
+		"Query overrides header": {
+			query:   map[string]string{"segment": "loyalty"},
+			headers: map[string]string{"X-Pricing-Segment": "b2b"},
+			status:  http.StatusOK,
+			want:    []catalog.Price{62300, 69300, 49700},
+		},
+		"Channel and country": {
+			query:   map[string]string{"channel": "mobile", "country": "es"},
+			headers: map[string]string{"X-Pricing-Segment": "b2b"},
+			status:  http.StatusOK,
+			want:    []catalog.Price{53400, 59400, 35500},
+		},
+		"Invalid country": {
+			headers: map[string]string{"X-Pricing-Country": "Spain"},
+			status:  http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			query := map[string]string{"category": "boots"}
+			for k, v := range tc.query {
+				query[k] = v
+			}
+			req := newListProductsRequest(t, query)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			response := httptest.NewRecorder()
+			catalogService.ServeHTTP(response, req)
+
+			assert.Equal(t, tc.status, response.Code)
+			if tc.status != http.StatusOK {
+				return
+			}
+			var got []catalog.Price
+			for _, p := range newPaginatedDiscountedProductsFromJSON(t, response.Body).Items() {
+				got = append(got, p.Price.Final)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func newPaginatedDiscountedProductsFromJSON(t *testing.T, rdr io.Reader) *catalog.PaginatedDiscountedProducts {
 	t.Helper()
 	var products *catalog.PaginatedDiscountedProducts
@@ -0,0 +1,675 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/amelendres/go-catalog/exporting"
+	"github.com/gorilla/mux"
+)
+
+const openAPIVersion = "3.0.3"
+
+// document is the subset of the OpenAPI 3 model the catalog routes need.
+type document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       info                `json:"info"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components components          `json:"components"`
+}
+
+type info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type pathItem map[string]*operation
+
+type components struct {
+	Schemas         map[string]*schema         `json:"schemas"`
+	SecuritySchemes map[string]*securityScheme `json:"securitySchemes,omitempty"`
+}
+
+type securityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+type operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+func ref(name string) *schema {
+	return &schema{Ref: "#/components/schemas/" + name}
+}
+
+func intSchema(minimum int) *schema {
+	return &schema{Type: "integer", Minimum: &minimum}
+}
+
+func jsonContent(s *schema) map[string]mediaType {
+	return map[string]mediaType{jsonContentType: {s}}
+}
+
+func errorResponse(description string) response {
+	return response{description, jsonContent(ref("Error"))}
+}
+
+var componentSchemas = map[string]*schema{
+	"Pagination": {
+		Type:       "object",
+		Properties: map[string]*schema{"limit": {Type: "integer"}, "offset": {Type: "integer"}},
+		Required:   []string{"limit", "offset"},
+	},
+	"PaginationMeta": {
+		Type:       "object",
+		Properties: map[string]*schema{"total": {Type: "integer"}, "pagination": ref("Pagination")},
+		Required:   []string{"total", "pagination"},
+	},
+	"DiscountedPrice": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"original":             {Type: "integer", Format: "int64"},
+			"final":                {Type: "integer", Format: "int64"},
+			"discount_percentage":  {Type: "integer", Nullable: true},
+			"currency":             {Type: "string"},
+			"lowest_30_days_price": {Type: "integer", Format: "int64", Nullable: true},
+			"promo_code":           {Type: "string", Description: "promo code of the applied discount"},
+			"explanation":          ref("PriceExplanation"),
+		},
+		Required: []string{"original", "final", "discount_percentage", "currency"},
+	},
+	"PricingContext": {
+		Type:        "object",
+		Description: "who prices are for, the unset fields of a discount audience matching any",
+		Properties: map[string]*schema{
+			"segment": {Type: "string"},
+			"channel": {Type: "string"},
+			"country": {Type: "string"},
+		},
+	},
+	"PriceExplanation": {
+		Type: "object",
+		Properties: map[string]*schema{"candidates": {Type: "array", Items: &schema{
+			Type: "object",
+			Properties: map[string]*schema{
+				"source":     {Type: "string", Enum: []string{"product", "category", "coupon"}},
+				"target":     {Type: "string", Description: "sku, category or promo code"},
+				"percentage": {Type: "integer"},
+				"audience":   ref("PricingContext"),
+				"applied":    {Type: "boolean"},
+				"reason":     {Type: "string"},
+			},
+			Required: []string{"source", "target", "percentage", "applied", "reason"},
+		}}},
+		Required: []string{"candidates"},
+	},
+	"DiscountedProduct": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"sku":      {Type: "string"},
+			"name":     {Type: "string"},
+			"category": {Type: "string"},
+			"price":    ref("DiscountedPrice"),
+		},
+		Required: []string{"sku", "name", "category", "price"},
+	},
+	"PaginatedDiscountedProducts": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"meta":  ref("PaginationMeta"),
+			"items": {Type: "array", Items: ref("DiscountedProduct"), Nullable: true},
+		},
+		Required: []string{"meta", "items"},
+	},
+	"PriceHistory": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"sku":      {Type: "string"},
+			"category": {Type: "string"},
+			"prices": {Type: "array", Items: &schema{
+				Type:       "object",
+				Properties: map[string]*schema{"price": {Type: "integer", Format: "int64"}, "at": {Type: "string", Format: "date-time"}},
+				Required:   []string{"price", "at"},
+			}},
+			"discounts": {Type: "array", Items: &schema{
+				Type: "object",
+				Properties: map[string]*schema{
+					"type":       {Type: "string", Enum: []string{"product", "category"}},
+					"target":     {Type: "string"},
+					"percentage": {Type: "integer", Nullable: true},
+					"at":         {Type: "string", Format: "date-time"},
+				},
+				Required: []string{"type", "target", "percentage", "at"},
+			}},
+		},
+		Required: []string{"sku", "category", "prices", "discounts"},
+	},
+	"PriceableItem": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"id":         {Type: "string"},
+			"group":      {Type: "string"},
+			"amount":     {Type: "integer", Format: "int64", Description: "minor units of the currency"},
+			"currency":   {Type: "string"},
+			"promo_code": {Type: "string"},
+			"segment":    {Type: "string"},
+			"channel":    {Type: "string"},
+			"country":    {Type: "string"},
+		},
+		Required: []string{"id", "amount"},
+	},
+	"PricingRule": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"scope":      {Type: "string", Enum: []string{"item", "group"}},
+			"target":     {Type: "string"},
+			"percentage": {Type: "integer"},
+			"segment":    {Type: "string", Description: "unset matching any"},
+			"channel":    {Type: "string", Description: "unset matching any"},
+			"country":    {Type: "string", Description: "unset matching any"},
+			"promo_code": {Type: "string", Description: "promo code the rule is for"},
+		},
+		Required: []string{"scope", "target", "percentage"},
+	},
+	"PriceResult": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"original": {Type: "integer", Format: "int64"},
+			"final":    {Type: "integer", Format: "int64"},
+			"currency": {Type: "string"},
+			"applied":  {Ref: "#/components/schemas/PricingRule", Nullable: true},
+			"candidates": {Type: "array", Items: &schema{
+				Type: "object",
+				Properties: map[string]*schema{
+					"rule":    ref("PricingRule"),
+					"applied": {Type: "boolean"},
+					"reason":  {Type: "string"},
+				},
+				Required: []string{"rule", "applied", "reason"},
+			}},
+		},
+		Required: []string{"original", "final", "currency", "applied"},
+	},
+	"PriceSimulation": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"meta": ref("PaginationMeta"),
+			"items": {Type: "array", Nullable: true, Items: &schema{
+				Type: "object",
+				Properties: map[string]*schema{
+					"sku":      {Type: "string"},
+					"name":     {Type: "string"},
+					"category": {Type: "string"},
+					"before":   ref("DiscountedPrice"),
+					"after":    ref("DiscountedPrice"),
+				},
+				Required: []string{"sku", "name", "category", "before", "after"},
+			}},
+			"aggregates": {
+				Type: "object",
+				Properties: map[string]*schema{
+					"products":                     {Type: "integer"},
+					"affected":                     {Type: "integer"},
+					"average_reduction":            {Type: "integer", Format: "int64"},
+					"average_reduction_percentage": {Type: "number"},
+				},
+				Required: []string{"products", "affected", "average_reduction", "average_reduction_percentage"},
+			},
+		},
+		Required: []string{"meta", "items", "aggregates"},
+	},
+	"Coupon": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"code":        {Type: "string", Description: "matched ignoring case"},
+			"percentage":  {Type: "integer"},
+			"valid_from":  {Type: "string", Format: "date-time", Nullable: true},
+			"valid_until": {Type: "string", Format: "date-time", Nullable: true},
+			"max_uses":    {Type: "integer", Description: "0 for unlimited"},
+			"uses":        {Type: "integer"},
+			"eligibility": {
+				Type:        "object",
+				Description: "products the code applies to, any when unset",
+				Properties: map[string]*schema{
+					"categories": {Type: "array", Items: &schema{Type: "string"}},
+					"skus":       {Type: "array", Items: &schema{Type: "string"}},
+					"min_price":  {Type: "integer", Format: "int64", Description: "minimum original price in cents"},
+				},
+			},
+		},
+		Required: []string{"code", "percentage", "uses"},
+	},
+	"CouponValidation": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"code":       {Type: "string"},
+			"sku":        {Type: "string"},
+			"at":         {Type: "string", Format: "date-time"},
+			"percentage": {Type: "integer"},
+			"applicable": {Type: "boolean"},
+			"reasons":    {Type: "array", Items: &schema{Type: "string"}, Description: "why the code is not applicable"},
+		},
+		Required: []string{"code", "at", "percentage", "applicable", "reasons"},
+	},
+	"Subscription": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"id":         {Type: "string"},
+			"url":        {Type: "string", Format: "uri"},
+			"secret":     {Type: "string"},
+			"created_at": {Type: "string", Format: "date-time"},
+		},
+		Required: []string{"id", "url", "created_at"},
+	},
+	"WebhookPayload": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"id":          {Type: "string"},
+			"type":        {Type: "string", Enum: []string{"price.changed"}},
+			"occurred_at": {Type: "string", Format: "date-time"},
+			"sku":         {Type: "string"},
+			"before":      ref("DiscountedPrice"),
+			"after":       ref("DiscountedPrice"),
+		},
+		Required: []string{"id", "type", "occurred_at", "sku", "before", "after"},
+	},
+	"DeadLetter": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"subscription_id": {Type: "string"},
+			"url":             {Type: "string", Format: "uri"},
+			"payload":         ref("WebhookPayload"),
+			"attempts":        {Type: "integer"},
+			"error":           {Type: "string"},
+			"failed_at":       {Type: "string", Format: "date-time"},
+		},
+		Required: []string{"subscription_id", "url", "payload", "attempts", "error", "failed_at"},
+	},
+	"HealthReport": {
+		Type: "object",
+		Properties: map[string]*schema{
+			"status": {Type: "string", Enum: []string{"up", "down"}},
+			"components": {Type: "object", AdditionalProperties: &schema{
+				Type: "object",
+				Properties: map[string]*schema{
+					"status": {Type: "string", Enum: []string{"up", "down"}},
+					"error":  {Type: "string"},
+				},
+				Required: []string{"status"},
+			}},
+		},
+		Required: []string{"status"},
+	},
+	"Error": {
+		Type: "object",
+		Properties: map[string]*schema{"error": {
+			Type:       "object",
+			Properties: map[string]*schema{"code": {Type: "string"}, "message": {Type: "string"}},
+			Required:   []string{"code", "message"},
+		}},
+		Required: []string{"error"},
+	},
+}
+
+func (cs *CatalogServer) listProductsOperation() *operation {
+	limit := intSchema(1)
+	limit.Default = cs.defaultLimit
+	offset := intSchema(0)
+	offset.Default = defaultOffset
+	return &operation{
+		OperationID: "listProducts",
+		Summary:     "List products with their discounted price",
+		Parameters: append([]parameter{
+			{Name: "limit", In: "query", Description: "page size, values above " + strconv.Itoa(cs.maxLimit) + " are capped to it", Schema: limit},
+			{Name: "offset", In: "query", Schema: offset},
+			{Name: "category", In: "query", Schema: &schema{Type: "string"}},
+			{Name: "priceLessThan", In: "query", Description: "original price in cents", Schema: &schema{Type: "integer"}},
+		}, pricingParameters()...),
+		Responses: map[string]response{
+			"200": {"Page of discounted products", jsonContent(ref("PaginatedDiscountedProducts"))},
+			"400": errorResponse("Invalid query parameters"),
+			"503": errorResponse("Storage unavailable"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func getProductOperation() *operation {
+	return &operation{
+		OperationID: "getProduct",
+		Summary:     "Get a product with its discounted price",
+		Parameters: append([]parameter{
+			{Name: "sku", In: "path", Required: true, Schema: &schema{Type: "string"}},
+		}, pricingParameters()...),
+		Responses: map[string]response{
+			"200": {"Discounted product", jsonContent(ref("DiscountedProduct"))},
+			"400": errorResponse("Invalid query parameters"),
+			"404": errorResponse("Unknown product"),
+			"503": errorResponse("Storage unavailable"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func streamProductsOperation() *operation {
+	return &operation{
+		OperationID: "streamProducts",
+		Summary:     "Stream the discounted product updates as Server-Sent Events",
+		Parameters: []parameter{
+			{Name: "category", In: "query", Schema: &schema{Type: "string"}},
+			{Name: "sku", In: "query", Schema: &schema{Type: "string"}},
+			{Name: "Last-Event-ID", In: "header", Description: "resume after this update id, the epoch of the server and a sequence number", Schema: &schema{Type: "string"}},
+		},
+		Responses: map[string]response{
+			"200": {"product events, their data being a DiscountedProduct", map[string]mediaType{"text/event-stream": {&schema{Type: "string"}}}},
+			"400": errorResponse("Invalid Last-Event-ID"),
+		},
+	}
+}
+
+func priceHistoryOperation() *operation {
+	return &operation{
+		OperationID: "getPriceHistory",
+		Summary:     "Price and discount changes of a product",
+		Parameters: []parameter{
+			{Name: "sku", In: "path", Required: true, Schema: &schema{Type: "string"}},
+		},
+		Responses: map[string]response{
+			"200": {"Price history", jsonContent(ref("PriceHistory"))},
+			"404": errorResponse("Unknown product"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func exportProductsOperation() *operation {
+	var formats []string
+	for _, f := range exporting.Formats {
+		formats = append(formats, string(f))
+	}
+	content := make(map[string]mediaType)
+	for _, f := range exporting.Formats {
+		content[f.ContentType()] = mediaType{&schema{Type: "string", Format: "binary"}}
+	}
+	return &operation{
+		OperationID: "exportProducts",
+		Summary:     "Export the discounted product feed",
+		Parameters: []parameter{
+			{Name: "format", In: "query", Schema: &schema{Type: "string", Enum: formats, Default: string(exporting.CSVFormat)}},
+		},
+		Responses: map[string]response{
+			"200": {"Product feed attachment", content},
+			"400": errorResponse("Unknown format"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+// cacheableOperation documents the conditional requests of the cacheable routes.
+func (cs *CatalogServer) cacheableOperation(op *operation) *operation {
+	if len(cs.versions) == 0 {
+		return op
+	}
+	op.Parameters = append(op.Parameters, parameter{Name: "If-None-Match", In: "header", Schema: &schema{Type: "string"}})
+	op.Responses["304"] = response{Description: "Not modified since the given ETag"}
+	return op
+}
+
+func calculatePriceOperation() *operation {
+	return &operation{
+		OperationID: "calculatePrice",
+		Summary:     "Price an item with the pricing rules",
+		RequestBody: &requestBody{Required: true, Content: jsonContent(ref("PriceableItem"))},
+		Responses: map[string]response{
+			"200": {"Price of the item", jsonContent(ref("PriceResult"))},
+			"400": errorResponse("Invalid item"),
+			"503": errorResponse("Storage unavailable"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func (cs *CatalogServer) simulatePricesOperation() *operation {
+	op := cs.listProductsOperation()
+	return &operation{
+		OperationID: "simulatePrices",
+		Summary:     "Price the products with proposed discounts, without saving them",
+		Parameters:  op.Parameters,
+		RequestBody: &requestBody{Required: true, Content: jsonContent(&schema{
+			Type: "object",
+			Properties: map[string]*schema{
+				"discounts": {Type: "array", Items: &schema{
+					Type: "object",
+					Properties: map[string]*schema{
+						"type":       {Type: "string", Enum: []string{"product", "category"}},
+						"target":     {Type: "string"},
+						"percentage": {Type: "integer", Description: "0 removes the discount of the target"},
+						"audience":   ref("PricingContext"),
+					},
+					Required: []string{"type", "target", "percentage"},
+				}},
+				"at": {Type: "string", Format: "date-time", Description: "start of the discounts, the lowest 30 days price is taken at, now by default"},
+			},
+			Required: []string{"discounts"},
+		})},
+		Responses: map[string]response{
+			"200": {"Prices before and after the proposed discounts", jsonContent(ref("PriceSimulation"))},
+			"400": errorResponse("Invalid proposal or query parameters"),
+			"503": errorResponse("Storage unavailable"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func validateCouponOperation() *operation {
+	return &operation{
+		OperationID: "validateCoupon",
+		Summary:     "Tell whether a promo code can be used and why not",
+		Parameters: []parameter{
+			{Name: "code", In: "path", Required: true, Schema: &schema{Type: "string"}},
+			{Name: "sku", In: "query", Description: "product the code would apply to", Schema: &schema{Type: "string"}},
+		},
+		Responses: map[string]response{
+			"200": {"Validation of the promo code", jsonContent(ref("CouponValidation"))},
+			"404": errorResponse("Unknown promo code or product"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func redeemCouponOperation() *operation {
+	return &operation{
+		OperationID: "redeemCoupon",
+		Summary:     "Count a use of a promo code",
+		Parameters: []parameter{
+			{Name: "code", In: "path", Required: true, Schema: &schema{Type: "string"}},
+		},
+		Responses: map[string]response{
+			"200": {"Promo code with its uses", jsonContent(ref("Coupon"))},
+			"404": errorResponse("Unknown promo code"),
+			"409": errorResponse("Promo code cannot be used"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func registerWebhookOperation() *operation {
+	return &operation{
+		OperationID: "registerWebhook",
+		Summary:     "Subscribe an endpoint to the final price changes",
+		RequestBody: &requestBody{Required: true, Content: jsonContent(&schema{
+			Type: "object",
+			Properties: map[string]*schema{
+				"url":    {Type: "string", Format: "uri"},
+				"secret": {Type: "string", Description: "HMAC key of the payload signatures, generated when empty"},
+			},
+			Required: []string{"url"},
+		})},
+		Responses: map[string]response{
+			"201": {"Subscription, with its secret", jsonContent(ref("Subscription"))},
+			"400": errorResponse("Invalid subscription"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func listWebhooksOperation() *operation {
+	return &operation{
+		OperationID: "listWebhooks",
+		Summary:     "List the webhook subscriptions",
+		Responses: map[string]response{
+			"200": {"Subscriptions, without their secrets", jsonContent(&schema{Type: "array", Items: ref("Subscription")})},
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func unregisterWebhookOperation() *operation {
+	return &operation{
+		OperationID: "unregisterWebhook",
+		Summary:     "Unsubscribe an endpoint",
+		Parameters: []parameter{
+			{Name: "id", In: "path", Required: true, Schema: &schema{Type: "string"}},
+		},
+		Responses: map[string]response{
+			"204": {Description: "Unsubscribed"},
+			"404": errorResponse("Unknown subscription"),
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func webhookDeadLettersOperation() *operation {
+	return &operation{
+		OperationID: "listWebhookDeadLetters",
+		Summary:     "Payloads not delivered after every retry",
+		Responses: map[string]response{
+			"200": {"Dead letters", jsonContent(&schema{Type: "array", Items: ref("DeadLetter")})},
+			"500": errorResponse("Internal error"),
+		},
+	}
+}
+
+func healthOperation(id, summary string) *operation {
+	return &operation{
+		OperationID: id,
+		Summary:     summary,
+		Responses: map[string]response{
+			"200": {"Up", jsonContent(ref("HealthReport"))},
+			"503": {"Down", jsonContent(ref("HealthReport"))},
+		},
+	}
+}
+
+func metricsOperation() *operation {
+	return &operation{
+		OperationID: "metrics",
+		Summary:     "Prometheus metrics",
+		Responses: map[string]response{
+			"200": {"Metrics in the Prometheus text format", map[string]mediaType{"text/plain": {&schema{Type: "string"}}}},
+		},
+	}
+}
+
+func graphQLOperation() *operation {
+	return &operation{
+		OperationID: "graphql",
+		Summary:     "Query products, categories and discounts with GraphQL",
+		RequestBody: &requestBody{Required: true, Content: jsonContent(&schema{
+			Type: "object",
+			Properties: map[string]*schema{
+				"query":         {Type: "string"},
+				"operationName": {Type: "string"},
+				"variables":     {Type: "object"},
+			},
+			Required: []string{"query"},
+		})},
+		Responses: map[string]response{
+			"200": {"GraphQL response", jsonContent(&schema{Type: "object"})},
+			"400": {"Invalid request body", jsonContent(&schema{Type: "object"})},
+		},
+	}
+}
+
+func openAPIOperation() *operation {
+	return &operation{
+		OperationID: "openapi",
+		Summary:     "This OpenAPI document",
+		Responses: map[string]response{
+			"200": {"OpenAPI 3 document", jsonContent(&schema{Type: "object"})},
+		},
+	}
+}
+
+// handle registers h on the router and documents it in the OpenAPI document,
+// so the served document always matches the routes.
+func (cs *CatalogServer) handle(router *mux.Router, path, method string, h http.Handler, op *operation) {
+	router.Handle(path, h).Methods(method)
+	if cs.spec.Paths[path] == nil {
+		cs.spec.Paths[path] = make(pathItem)
+	}
+	cs.spec.Paths[path][strings.ToLower(method)] = op
+}
+
+func (cs *CatalogServer) operation(path, method string) *operation {
+	return cs.spec.Paths[path][strings.ToLower(method)]
+}
+
+func (cs *CatalogServer) openAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(cs.spec)
+}
+
+func newDocument() *document {
+	return &document{
+		OpenAPI:    openAPIVersion,
+		Info:       info{Title: "Catalog API", Version: "1.0.0"},
+		Paths:      make(map[string]pathItem),
+		Components: components{Schemas: componentSchemas},
+	}
+}
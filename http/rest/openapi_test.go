@@ -0,0 +1,129 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/metrics"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDocumentedServer() *rest.CatalogServer {
+	productRepo := inmem.NewProductRepo(givenProducts)
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)})
+	productLister := listing.NewProductLister(productRepo, pricingacl.NewCalculater(discountRepo))
+	graphql := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	return rest.NewCatalogServer(productLister, rest.WithMetrics(metrics.NewRegistry()), rest.WithGraphQL(graphql))
+}
+
+func TestCatalogServer_openAPI(t *testing.T) {
+	cs := newDocumentedServer()
+
+	response := httptest.NewRecorder()
+	cs.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/json", response.Header().Get("content-type"))
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&doc); err != nil {
+		t.Fatalf("fails decoding the OpenAPI document %v", err)
+	}
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Contains(t, doc.Components.Schemas, "PaginatedDiscountedProducts")
+	assert.NotContains(t, string(doc.Paths["/products"]["get"]), "maximum", "larger limits are capped, not rejected")
+
+	var routes []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			routes = append(routes, strings.ToUpper(method)+" "+path)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"GET /products",
+		"GET /products/{sku}",
+		"GET /exports/products",
+		"GET /healthz",
+		"GET /readyz",
+		"GET /metrics",
+		"POST /graphql",
+		"GET /openapi.json",
+	}, routes)
+
+	for _, route := range routes {
+		parts := strings.SplitN(route, " ", 2)
+		target := strings.Replace(parts[1], "{sku}", string(givenProducts[0].SKU), 1)
+		response := httptest.NewRecorder()
+		cs.ServeHTTP(response, httptest.NewRequest(parts[0], target, nil))
+		assert.NotContains(t, []int{http.StatusNotFound, http.StatusMethodNotAllowed}, response.Code, route)
+	}
+}
+
+func TestCatalogServer_validateRequests(t *testing.T) {
+	cs := newDocumentedServer()
+
+	tests := map[string]struct {
+		target  string
+		status  int
+		message string
+	}{
+		"Limit below the minimum": {
+			target:  "/products?limit=0",
+			status:  http.StatusBadRequest,
+			message: "limit must be greater than 0, got 0",
+		},
+		"Offset not an integer": {
+			target:  "/products?offset=first",
+			status:  http.StatusBadRequest,
+			message: `offset must be an integer, got "first"`,
+		},
+		"Limit above the maximum is capped": {
+			target: "/products?limit=1000",
+			status: http.StatusOK,
+		},
+		"Unknown export format": {
+			target:  "/exports/products?format=pdf",
+			status:  http.StatusBadRequest,
+			message: `format must be one of csv, jsonl, merchant-xml, merchant-tsv, got "pdf"`,
+		},
+		"Export format in upper case": {
+			target: "/exports/products?format=CSV",
+			status: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			cs.ServeHTTP(response, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.status, response.Code)
+			if tt.message == "" {
+				return
+			}
+			var body struct {
+				Error struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			_ = json.NewDecoder(response.Body).Decode(&body)
+			assert.Equal(t, "invalid_argument", body.Error.Code)
+			assert.Equal(t, tt.message, body.Error.Message)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/history"
+	"github.com/gorilla/mux"
+)
+
+// WithPriceHistory serves the price history of the products at
+// GET /products/{sku}/price-history.
+func WithPriceHistory(h *history.Service) Option {
+	return func(cs *CatalogServer) {
+		cs.history = h
+	}
+}
+
+func (cs *CatalogServer) priceHistory(w http.ResponseWriter, r *http.Request) {
+	sku := catalog.SKU(mux.Vars(r)["sku"])
+	h, err := cs.history.PriceHistory(r.Context(), cs.productLister, sku)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(h)
+}
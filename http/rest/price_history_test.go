@@ -0,0 +1,57 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/history"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogServer_priceHistory(t *testing.T) {
+	at := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	service := history.NewService(inmem.NewHistoryRepo(), history.WithClock(func() time.Time { return at }))
+	productRepo := inmem.NewProductRepo(nil)
+	discountRepo := inmem.NewDiscountRepo(nil)
+	_ = service.ProductStore(productRepo).Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)})
+	_ = service.DiscountStore(discountRepo).Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)})
+
+	productLister := listing.NewProductLister(productRepo, pricingacl.NewCalculater(discountRepo))
+	cs := rest.NewCatalogServer(productLister, rest.WithPriceHistory(service))
+
+	tests := map[string]struct {
+		sku    string
+		status int
+		body   string
+	}{
+		"Known product": {
+			sku:    "000001",
+			status: http.StatusOK,
+			body: `{"sku":"000001","category":"boots",
+				"prices":[{"price":89000,"at":"2022-03-01T10:00:00Z"}],
+				"discounts":[{"type":"category","target":"boots","percentage":30,"at":"2022-03-01T10:00:00Z"}]}`,
+		},
+		"Unknown product": {
+			sku:    "999999",
+			status: http.StatusNotFound,
+			body:   `{"error":{"code":"not_found","message":"product \"999999\" not found"}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			cs.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/products/"+tt.sku+"/price-history", nil))
+
+			assert.Equal(t, tt.status, response.Code)
+			assert.JSONEq(t, tt.body, response.Body.String())
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/pricing"
+)
+
+// WithPricing serves the pricing context at /pricing.
+func WithPricing(e pricing.Engine) Option {
+	return func(cs *CatalogServer) {
+		cs.pricing = e
+	}
+}
+
+func (cs *CatalogServer) calculatePrice(w http.ResponseWriter, r *http.Request) {
+	var it pricing.Item
+	if err := json.NewDecoder(r.Body).Decode(&it); err != nil {
+		writeError(w, r, catalog.NewInvalidArgumentError("invalid request body %v", err))
+		return
+	}
+	if it.ID == "" {
+		writeError(w, r, catalog.NewInvalidArgumentError("item id is required"))
+		return
+	}
+	if it.Amount < 0 {
+		writeError(w, r, catalog.NewInvalidArgumentError("amount must not be negative, got %d", it.Amount))
+		return
+	}
+
+	result, err := cs.pricing.Price(r.Context(), it)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(result)
+}
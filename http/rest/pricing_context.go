@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+// pricingContextHeaders select the pricing context of the request, unless
+// overridden by the segment, channel and country query parameters.
+var pricingContextHeaders = []string{"X-Pricing-Segment", "X-Pricing-Channel", "X-Pricing-Country"}
+
+func pricingContext(r *http.Request) (catalog.PricingContext, error) {
+	value := func(param, header string) string {
+		if v := r.URL.Query().Get(param); v != "" {
+			return v
+		}
+		return r.Header.Get(header)
+	}
+	pc := catalog.PricingContext{
+		Segment: value("segment", pricingContextHeaders[0]),
+		Channel: value("channel", pricingContextHeaders[1]),
+		Country: strings.ToUpper(value("country", pricingContextHeaders[2])),
+	}
+	if pc.Country != "" && !isCountryCode(pc.Country) {
+		return pc, catalog.NewInvalidArgumentError("country must be an ISO 3166-1 alpha-2 code, got %q", pc.Country)
+	}
+	return pc, nil
+}
+
+func isCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// pricedFor prices the products of r for its pricing context, with its promo
+// code and explained, as requested.
+func pricedFor(r *http.Request) (*http.Request, error) {
+	pc, err := pricingContext(r)
+	if err != nil {
+		return r, err
+	}
+	ctx := r.Context()
+	if !pc.IsZero() {
+		ctx = catalog.WithPricingContext(ctx, pc)
+	}
+	if code := r.URL.Query().Get("promoCode"); code != "" {
+		ctx = catalog.WithPromoCode(ctx, code)
+	}
+	if explain, _ := strconv.ParseBool(r.URL.Query().Get("explain")); explain {
+		ctx = catalog.WithExplanation(ctx)
+	}
+	return r.WithContext(ctx), nil
+}
+
+// pricingParameters are the query parameters and headers read by pricedFor.
+func pricingParameters() []parameter {
+	return []parameter{
+		{Name: "explain", In: "query", Description: "explain which discounts matched each price and which was applied", Schema: &schema{Type: "boolean", Default: false}},
+		{Name: "promoCode", In: "query", Description: "promo code applied to the eligible products, ignored when unknown", Schema: &schema{Type: "string"}},
+		{Name: "segment", In: "query", Description: "customer segment the prices are for, as b2b or loyalty", Schema: &schema{Type: "string"}},
+		{Name: "channel", In: "query", Description: "sales channel the prices are for, as web or mobile", Schema: &schema{Type: "string"}},
+		{Name: "country", In: "query", Description: "ISO 3166-1 alpha-2 country the prices are for", Schema: &schema{Type: "string"}},
+		{Name: pricingContextHeaders[0], In: "header", Description: "segment, unless set by the query", Schema: &schema{Type: "string"}},
+		{Name: pricingContextHeaders[1], In: "header", Description: "channel, unless set by the query", Schema: &schema{Type: "string"}},
+		{Name: pricingContextHeaders[2], In: "header", Description: "country, unless set by the query", Schema: &schema{Type: "string"}},
+	}
+}
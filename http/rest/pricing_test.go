@@ -0,0 +1,59 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogServer_calculatePrice(t *testing.T) {
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)})
+	productLister := listing.NewProductLister(inmem.NewProductRepo(nil), pricingacl.NewCalculater(discountRepo))
+	cs := rest.NewCatalogServer(productLister, rest.WithPricing(pricing.NewEngine(pricingacl.NewRules(discountRepo))))
+
+	tests := map[string]struct {
+		body   string
+		status int
+		want   string
+	}{
+		"Rule applied": {
+			body:   `{"id":"000001","group":"boots","amount":89000,"currency":"EUR"}`,
+			status: http.StatusOK,
+			want:   `{"original":89000,"final":62300,"currency":"EUR","applied":{"scope":"group","target":"boots","percentage":30},"candidates":[{"rule":{"scope":"group","target":"boots","percentage":30},"applied":true,"reason":"highest percentage"}]}`,
+		},
+		"No rule": {
+			body:   `{"id":"000004","group":"sandals","amount":79500,"currency":"EUR"}`,
+			status: http.StatusOK,
+			want:   `{"original":79500,"final":79500,"currency":"EUR","applied":null}`,
+		},
+		"Without id": {
+			body:   `{"group":"boots","amount":89000}`,
+			status: http.StatusBadRequest,
+			want:   `{"error":{"code":"invalid_argument","message":"item id is required"}}`,
+		},
+		"Negative amount": {
+			body:   `{"id":"000001","amount":-1}`,
+			status: http.StatusBadRequest,
+			want:   `{"error":{"code":"invalid_argument","message":"amount must not be negative, got -1"}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			cs.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/pricing/calculate", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.status, response.Code)
+			assert.JSONEq(t, tt.want, response.Body.String())
+		})
+	}
+}
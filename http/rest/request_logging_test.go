@@ -0,0 +1,74 @@
+package rest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/testing/stub"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCatalogServer_requestLogging(t *testing.T) {
+	discountRepoErr := errors.New("fails discount repository")
+	pricingCalculater := pricingacl.NewCalculater(stub.NewStubDiscountRepo(nil, discountRepoErr))
+	productLister := listing.NewProductLister(inmem.NewProductRepo(givenProducts), pricingCalculater)
+
+	tests := map[string]struct {
+		requestID     string
+		wantRequestID func(id string) bool
+	}{
+		"Accepts request ID": {
+			requestID:     "checkout-42",
+			wantRequestID: func(id string) bool { return id == "checkout-42" },
+		},
+		"Generates request ID": {
+			requestID:     "",
+			wantRequestID: func(id string) bool { return len(id) == 32 },
+		},
+		"Replaces invalid request ID": {
+			requestID:     "bad id\n",
+			wantRequestID: func(id string) bool { return len(id) == 32 },
+		},
+	}
+
+	for name, tc := range tests {
+		core, logs := observer.New(zap.InfoLevel)
+		catalogService := rest.NewCatalogServer(productLister, rest.WithLogger(zap.New(core)))
+
+		req := httptest.NewRequest(http.MethodGet, "/products?category=boots&limit=2", nil)
+		req.Header.Set("X-Request-ID", tc.requestID)
+		response := httptest.NewRecorder()
+		catalogService.ServeHTTP(response, req)
+
+		id := response.Header().Get("X-Request-ID")
+		assert.True(t, tc.wantRequestID(id), "%s: unexpected request ID %q", name, id)
+		assert.Equal(t, http.StatusInternalServerError, response.Code, name)
+
+		pricingErrs := logs.FilterMessage("could not find discounts").AllUntimed()
+		if assert.Len(t, pricingErrs, 1, name) {
+			fields := pricingErrs[0].ContextMap()
+			assert.Equal(t, id, fields["request_id"], name)
+			assert.Equal(t, discountRepoErr.Error(), fields["error"], name)
+			assert.Equal(t, map[string]interface{}{"category": "boots", "sku": "000001"}, fields["criteria"], name)
+		}
+
+		listErrs := logs.FilterMessage("request failed").AllUntimed()
+		if assert.Len(t, listErrs, 1, name) {
+			assert.Equal(t, map[string]interface{}{"limit": 2, "offset": 0, "category": "boots"}, listErrs[0].ContextMap()["criteria"], name)
+		}
+
+		requests := logs.FilterMessage("request").AllUntimed()
+		if assert.Len(t, requests, 1, name) {
+			assert.Equal(t, int64(http.StatusInternalServerError), requests[0].ContextMap()["status"], name)
+			assert.Equal(t, id, requests[0].ContextMap()["request_id"], name)
+		}
+	}
+}
@@ -4,46 +4,187 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/exporting"
+	"github.com/amelendres/go-catalog/health"
+	"github.com/amelendres/go-catalog/history"
 	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/logging"
+	"github.com/amelendres/go-catalog/metrics"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/simulation"
+	"github.com/amelendres/go-catalog/streaming"
+	"github.com/amelendres/go-catalog/tracing"
+	"github.com/amelendres/go-catalog/webhooks"
 	"github.com/gorilla/mux"
+	"go.uber.org/zap"
 )
 
 type CatalogServer struct {
 	productLister listing.ProductLister
+	exporter      exporting.Exporter
+	health        *health.Checker
+	metrics       *metrics.Registry
+	logger        *zap.Logger
+	tracer        *tracing.Tracer
+	graphql       http.Handler
+	history       *history.Service
+	webhooks      *webhooks.Service
+	coupons       *coupons.Service
+	pricing       pricing.Engine
+	simulator     *simulation.Simulator
+	stream        *streaming.Broker
+	heartbeat     time.Duration
+	spec          *document
+	versions      []catalog.Versioner
+	cacheControl  map[string]string
+	epoch         string
+	adminToken    string
+	defaultLimit  int
+	maxLimit      int
 	http.Handler
 }
 
+type Option func(cs *CatalogServer)
+
+func WithExporter(e exporting.Exporter) Option {
+	return func(cs *CatalogServer) {
+		cs.exporter = e
+	}
+}
+
+func WithHealthChecker(c *health.Checker) Option {
+	return func(cs *CatalogServer) {
+		cs.health = c
+	}
+}
+
+// WithMetrics instruments every request and serves the registry at /metrics.
+func WithMetrics(m *metrics.Registry) Option {
+	return func(cs *CatalogServer) {
+		cs.metrics = m
+	}
+}
+
+func WithLogger(l *zap.Logger) Option {
+	return func(cs *CatalogServer) {
+		cs.logger = l
+	}
+}
+
+func WithTracer(t *tracing.Tracer) Option {
+	return func(cs *CatalogServer) {
+		cs.tracer = t
+	}
+}
+
+// WithGraphQL serves h at POST /graphql.
+func WithGraphQL(h http.Handler) Option {
+	return func(cs *CatalogServer) {
+		cs.graphql = h
+	}
+}
+
+func WithPageSize(defaultLimit, maxLimit int) Option {
+	return func(cs *CatalogServer) {
+		cs.defaultLimit = defaultLimit
+		cs.maxLimit = maxLimit
+	}
+}
+
 const (
 	jsonContentType = "application/json"
 
 	defaultLimit  = 5
+	maxLimit      = 100
 	defaultOffset = 0
 )
 
-func NewCatalogServer(pl listing.ProductLister) *CatalogServer {
+func NewCatalogServer(pl listing.ProductLister, opts ...Option) *CatalogServer {
 	cs := new(CatalogServer)
 	cs.productLister = pl
+	cs.exporter = exporting.NewExporter(pl, "")
+	cs.health = health.NewChecker(nil)
+	cs.logger = zap.NewNop()
+	cs.defaultLimit = defaultLimit
+	cs.maxLimit = maxLimit
+	cs.cacheControl = make(map[string]string)
+	cs.heartbeat = defaultHeartbeat
+	cs.epoch = newEpoch()
+	for _, opt := range opts {
+		opt(cs)
+	}
 
+	cs.spec = newDocument()
 	router := mux.NewRouter()
-	router.HandleFunc("/products", cs.listProducts).Methods(http.MethodGet)
+	router.NotFoundHandler = http.HandlerFunc(notFound)
+	router.MethodNotAllowedHandler = http.HandlerFunc(methodNotAllowed)
+	router.Use(cs.validateRequests)
+	cs.handle(router, "/products", http.MethodGet, cs.cacheable("/products", cs.listProducts, pricingContextHeaders...), cs.cacheableOperation(cs.listProductsOperation()))
+	if cs.stream != nil {
+		cs.handle(router, "/products/stream", http.MethodGet, http.HandlerFunc(cs.streamProducts), streamProductsOperation())
+	}
+	cs.handle(router, "/products/{sku}", http.MethodGet, cs.cacheable("/products/{sku}", cs.getProduct, pricingContextHeaders...), cs.cacheableOperation(getProductOperation()))
+	if cs.history != nil {
+		cs.handle(router, "/products/{sku}/price-history", http.MethodGet, http.HandlerFunc(cs.priceHistory), priceHistoryOperation())
+	}
+	cs.handle(router, "/exports/products", http.MethodGet, cs.cacheable("/exports/products", cs.exportProducts), cs.cacheableOperation(exportProductsOperation()))
+	cs.handle(router, "/healthz", http.MethodGet, http.HandlerFunc(cs.liveness), healthOperation("liveness", "Liveness probe"))
+	cs.handle(router, "/readyz", http.MethodGet, http.HandlerFunc(cs.readiness), healthOperation("readiness", "Readiness probe"))
+	if cs.pricing != nil {
+		cs.handle(router, "/pricing/calculate", http.MethodPost, http.HandlerFunc(cs.calculatePrice), calculatePriceOperation())
+	}
+	if cs.simulator != nil {
+		cs.handle(router, "/pricing/simulate", http.MethodPost, http.HandlerFunc(cs.simulatePrices), cs.simulatePricesOperation())
+	}
+	if cs.webhooks != nil {
+		cs.handleAdmin(router, "/webhooks", http.MethodPost, http.HandlerFunc(cs.registerWebhook), registerWebhookOperation())
+		cs.handleAdmin(router, "/webhooks", http.MethodGet, http.HandlerFunc(cs.listWebhooks), listWebhooksOperation())
+		cs.handleAdmin(router, "/webhooks/dead-letters", http.MethodGet, http.HandlerFunc(cs.webhookDeadLetters), webhookDeadLettersOperation())
+		cs.handleAdmin(router, "/webhooks/{id}", http.MethodDelete, http.HandlerFunc(cs.unregisterWebhook), unregisterWebhookOperation())
+	}
+	if cs.coupons != nil {
+		cs.handle(router, "/coupons/{code}/validate", http.MethodGet, http.HandlerFunc(cs.validateCoupon), validateCouponOperation())
+		cs.handleAdmin(router, "/coupons/{code}/redeem", http.MethodPost, http.HandlerFunc(cs.redeemCoupon), redeemCouponOperation())
+	}
+	if cs.graphql != nil {
+		cs.handle(router, "/graphql", http.MethodPost, cs.graphql, graphQLOperation())
+	}
+	if cs.metrics != nil {
+		cs.handle(router, "/metrics", http.MethodGet, cs.metrics.Handler(), metricsOperation())
+	}
+	cs.handle(router, "/openapi.json", http.MethodGet, http.HandlerFunc(cs.openAPI), openAPIOperation())
 
-	cs.Handler = router
+	var handler http.Handler = router
+	if cs.metrics != nil {
+		handler = instrument(router, cs.metrics)
+	}
+	handler = logRequests(handler, cs.logger)
+	if cs.tracer != nil {
+		handler = traceRequests(handler, router, cs.tracer)
+	}
+	cs.Handler = handler
 
 	return cs
 }
 
 func (cs *CatalogServer) listProducts(w http.ResponseWriter, r *http.Request) {
-	searchCriteria, err := buildSearchCriteria(r)
+	searchCriteria, err := cs.buildSearchCriteria(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, r, err)
+		return
+	}
+	if r, err = pricedFor(r); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	lp, err := cs.productLister.List(*searchCriteria)
+	lp, err := cs.productLister.List(r.Context(), *searchCriteria)
 	if err != nil {
-		w.WriteHeader(http.StatusConflict)
+		writeError(w, r, err, logging.Criteria(*searchCriteria))
 		return
 	}
 
@@ -51,20 +192,39 @@ func (cs *CatalogServer) listProducts(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(lp)
 }
 
-func buildSearchCriteria(r *http.Request) (search *catalog.SearchCriteria, err error) {
+func (cs *CatalogServer) getProduct(w http.ResponseWriter, r *http.Request) {
+	r, err := pricedFor(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	p, err := listing.GetProduct(r.Context(), cs.productLister, catalog.SKU(mux.Vars(r)["sku"]))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+func (cs *CatalogServer) buildSearchCriteria(r *http.Request) (search *catalog.SearchCriteria, err error) {
 	//pagination
-	iLimit := defaultLimit
+	iLimit := cs.defaultLimit
 	limit := r.URL.Query().Get("limit")
 	if limit != "" {
 		if iLimit, err = strconv.Atoi(limit); err != nil {
-			return nil, err
+			return nil, catalog.NewInvalidArgumentError("limit must be an integer, got %q", limit)
 		}
 	}
+	if iLimit > cs.maxLimit {
+		iLimit = cs.maxLimit
+	}
 	iOffset := defaultOffset
 	offset := r.URL.Query().Get("offset")
 	if offset != "" {
 		if iOffset, err = strconv.Atoi(offset); err != nil {
-			return nil, err
+			return nil, catalog.NewInvalidArgumentError("offset must be an integer, got %q", offset)
 		}
 	}
 
@@ -83,7 +243,7 @@ func buildSearchCriteria(r *http.Request) (search *catalog.SearchCriteria, err e
 	if priceLessThan != "" {
 		i, err := strconv.Atoi(priceLessThan)
 		if err != nil {
-			return nil, err
+			return nil, catalog.NewInvalidArgumentError("priceLessThan must be an integer, got %q", priceLessThan)
 		}
 		filters = append(filters, catalog.NewPriceLessThanFilter(catalog.Price(i)))
 	}
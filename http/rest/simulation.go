@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/simulation"
+)
+
+// WithSimulator serves price simulations at POST /pricing/simulate.
+func WithSimulator(s *simulation.Simulator) Option {
+	return func(cs *CatalogServer) {
+		cs.simulator = s
+	}
+}
+
+type proposedDiscount struct {
+	Type       string                 `json:"type"`
+	Target     string                 `json:"target"`
+	Percentage int                    `json:"percentage"`
+	Audience   catalog.PricingContext `json:"audience"`
+}
+
+type proposal struct {
+	Discounts []proposedDiscount `json:"discounts"`
+	At        *time.Time         `json:"at"`
+}
+
+func (p proposal) toProposal() (simulation.Proposal, error) {
+	var sp simulation.Proposal
+	if p.At != nil {
+		sp.At = *p.At
+	}
+	for i, d := range p.Discounts {
+		if d.Target == "" {
+			return sp, catalog.NewInvalidArgumentError("discounts[%d].target is required", i)
+		}
+		if d.Percentage < 0 || d.Percentage > 100 {
+			return sp, catalog.NewInvalidArgumentError("discounts[%d].percentage must be between 0 and 100, got %d", i, d.Percentage)
+		}
+		dp, audience := catalog.DiscountPercentage(d.Percentage), catalog.ForAudience(d.Audience)
+		switch d.Type {
+		case catalog.ProductDiscountType:
+			sp.Discounts = append(sp.Discounts, catalog.NewProductDiscount(catalog.SKU(d.Target), dp, audience))
+		case catalog.CategoryDiscountType:
+			sp.Discounts = append(sp.Discounts, catalog.NewCategoryDiscount(catalog.Category(d.Target), dp, audience))
+		default:
+			return sp, catalog.NewInvalidArgumentError("discounts[%d].type must be one of product, category, got %q", i, d.Type)
+		}
+	}
+	return sp, nil
+}
+
+func (cs *CatalogServer) simulatePrices(w http.ResponseWriter, r *http.Request) {
+	search, err := cs.buildSearchCriteria(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if r, err = pricedFor(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var body proposal
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, r, catalog.NewInvalidArgumentError("invalid request body %v", err))
+		return
+	}
+	p, err := body.toProposal()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	sim, err := cs.simulator.Simulate(r.Context(), p, *search)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(sim)
+}
@@ -0,0 +1,72 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/simulation"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogServer_simulatePrices(t *testing.T) {
+	productRepo := inmem.NewProductRepo(givenProducts)
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)})
+	productLister := listing.NewProductLister(productRepo, pricingacl.NewCalculater(discountRepo))
+	simulator := simulation.NewSimulator(productRepo, discountRepo, func(r catalog.DiscountRepository) catalog.Calculater {
+		return pricingacl.NewCalculater(r)
+	})
+	cs := rest.NewCatalogServer(productLister, rest.WithSimulator(simulator))
+
+	tests := map[string]struct {
+		target string
+		body   string
+		status int
+		want   string
+	}{
+		"Simulated": {
+			target: "/pricing/simulate?category=sandals",
+			body:   `{"discounts":[{"type":"category","target":"sandals","percentage":10}]}`,
+			status: http.StatusOK,
+			want: `{"meta":{"total":1,"pagination":{"limit":5,"offset":0}},
+				"items":[{"sku":"000004","name":"Naima embellished suede sandals","category":"sandals",
+					"before":{"original":79500,"final":79500,"discount_percentage":null,"currency":"EUR"},
+					"after":{"original":79500,"final":71550,"discount_percentage":10,"currency":"EUR"}}],
+				"aggregates":{"products":1,"affected":1,"average_reduction":7950,"average_reduction_percentage":10}}`,
+		},
+		"Unknown discount type": {
+			target: "/pricing/simulate",
+			body:   `{"discounts":[{"type":"brand","target":"BV","percentage":10}]}`,
+			status: http.StatusBadRequest,
+			want:   `{"error":{"code":"invalid_argument","message":"discounts[0].type must be one of product, category, got \"brand\""}}`,
+		},
+		"Percentage out of range": {
+			target: "/pricing/simulate",
+			body:   `{"discounts":[{"type":"product","target":"000001","percentage":120}]}`,
+			status: http.StatusBadRequest,
+			want:   `{"error":{"code":"invalid_argument","message":"discounts[0].percentage must be between 0 and 100, got 120"}}`,
+		},
+		"Invalid query": {
+			target: "/pricing/simulate?limit=0",
+			body:   `{"discounts":[]}`,
+			status: http.StatusBadRequest,
+			want:   `{"error":{"code":"invalid_argument","message":"limit must be greater than 0, got 0"}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			cs.ServeHTTP(response, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.status, response.Code)
+			assert.JSONEq(t, tt.want, response.Body.String())
+		})
+	}
+}
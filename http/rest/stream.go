@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/streaming"
+)
+
+const (
+	defaultHeartbeat = 15 * time.Second
+	// streamWriteTimeout bounds every write of a stream, which outlives the
+	// write timeout of the server.
+	streamWriteTimeout = 10 * time.Second
+)
+
+type connKey struct{}
+
+// ConnContext keeps the connection of the requests in their context, so
+// streams extend its write deadline. Set it as the http.Server ConnContext.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+// extendWriteDeadline lets the next write of the stream take up to
+// streamWriteTimeout, whatever the write timeout of the server.
+func extendWriteDeadline(r *http.Request) {
+	if c, ok := r.Context().Value(connKey{}).(net.Conn); ok {
+		_ = c.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	}
+}
+
+// WithStream streams the product updates of b as Server-Sent Events at
+// GET /products/stream, writing a heartbeat comment every heartbeat.
+func WithStream(b *streaming.Broker, heartbeat time.Duration) Option {
+	return func(cs *CatalogServer) {
+		cs.stream = b
+		cs.heartbeat = heartbeat
+	}
+}
+
+func (cs *CatalogServer) streamProducts(w http.ResponseWriter, r *http.Request) {
+	var lastID uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		epoch, id, err := parseEventID(v)
+		if err != nil {
+			writeError(w, r, catalog.NewInvalidArgumentError("Last-Event-ID must be an update id, got %q", v))
+			return
+		}
+		// the updates of a previous run are replayed from the first one kept.
+		if epoch == cs.stream.Epoch() {
+			lastID = id
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, fmt.Errorf("streaming is not supported by the connection"))
+		return
+	}
+	filter := streaming.Filter{
+		Category: catalog.Category(r.URL.Query().Get("category")),
+		SKU:      catalog.SKU(r.URL.Query().Get("sku")),
+	}
+	sub := cs.stream.Subscribe(filter, lastID)
+	defer cs.stream.Unsubscribe(sub)
+
+	w.Header().Set("content-type", "text/event-stream")
+	w.Header().Set("cache-control", "no-cache")
+	extendWriteDeadline(r)
+	w.WriteHeader(http.StatusOK)
+	for _, u := range sub.Replay {
+		if err := writeUpdate(w, cs.stream.Epoch(), u); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(cs.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case u, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			extendWriteDeadline(r)
+			if err := writeUpdate(w, cs.stream.Epoch(), u); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			extendWriteDeadline(r)
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// parseEventID splits an event ID into the epoch of the broker that sent it
+// and the update ID.
+func parseEventID(v string) (string, uint64, error) {
+	i := strings.LastIndex(v, "-")
+	if i < 0 {
+		return "", 0, fmt.Errorf("missing epoch in %q", v)
+	}
+	id, err := strconv.ParseUint(v[i+1:], 10, 64)
+	return v[:i], id, err
+}
+
+func writeUpdate(w http.ResponseWriter, epoch string, u streaming.Update) error {
+	data, err := json.Marshal(u.Product)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s-%d\nevent: product\ndata: %s\n\n", epoch, u.ID, data)
+	return err
+}
@@ -0,0 +1,120 @@
+package rest_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/streaming"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent returns the lines of the next event, up to the blank line.
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestCatalogServer_streamProducts(t *testing.T) {
+	ctx := context.Background()
+	products := inmem.NewProductRepo([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+		catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+	})
+	discounts := inmem.NewDiscountRepo(nil)
+	productLister := listing.NewProductLister(products, pricingacl.NewCalculater(discounts))
+	broker := streaming.NewBroker(productLister, streaming.WithEpoch("e1"))
+	srv := httptest.NewUnstartedServer(rest.NewCatalogServer(productLister, rest.WithStream(broker, 50*time.Millisecond)))
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Config.ConnContext = rest.ConnContext
+	srv.Start()
+	defer srv.Close()
+
+	_ = discounts.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)})
+	require.NoError(t, broker.Publish(ctx, catalog.NewDiscountAppliedEvent(catalog.NewCategoryDiscount("boots", givenCategoryDiscount))))
+
+	open := func(t *testing.T, query, lastEventID string) *bufio.Reader {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/products/stream"+query, nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("content-type"))
+		return bufio.NewReader(resp.Body)
+	}
+
+	t.Run("Updates matching the filter", func(t *testing.T) {
+		stream := open(t, "?category=sandals", "")
+
+		sandals := catalog.NewCategoryDiscount("sandals", 10)
+		_ = discounts.Save([]catalog.Discount{sandals})
+		require.NoError(t, broker.Publish(ctx, catalog.NewPriceChangedEvent(catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000), 99000)))
+		require.NoError(t, broker.Publish(ctx, catalog.NewDiscountAppliedEvent(sandals)))
+
+		assert.Equal(t, []string{
+			"id: e1-3",
+			"event: product",
+			`data: {"sku":"000004","name":"Naima embellished suede sandals","category":"sandals","price":{"original":79500,"final":71550,"discount_percentage":10,"currency":"EUR"}}`,
+		}, readEvent(t, stream))
+		assert.Equal(t, []string{": heartbeat"}, readEvent(t, stream))
+	})
+
+	t.Run("Outlives the server write timeout", func(t *testing.T) {
+		stream := open(t, "", "e1-3")
+
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, []string{": heartbeat"}, readEvent(t, stream))
+		}
+	})
+
+	t.Run("Resume after Last-Event-ID", func(t *testing.T) {
+		stream := open(t, "?sku=000001", "e1-1")
+
+		assert.Equal(t, []string{
+			"id: e1-2",
+			"event: product",
+			`data: {"sku":"000001","name":"BV Lean leather ankle boots","category":"boots","price":{"original":89000,"final":62300,"discount_percentage":30,"currency":"EUR"}}`,
+		}, readEvent(t, stream))
+	})
+
+	t.Run("Resume after a restart", func(t *testing.T) {
+		stream := open(t, "?sku=000001", "e0-7")
+
+		assert.Equal(t, []string{
+			"id: e1-1",
+			"event: product",
+			`data: {"sku":"000001","name":"BV Lean leather ankle boots","category":"boots","price":{"original":89000,"final":62300,"discount_percentage":30,"currency":"EUR"}}`,
+		}, readEvent(t, stream), "the IDs of a previous run replay every kept update")
+	})
+
+	t.Run("Invalid Last-Event-ID", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/products/stream", nil)
+		req.Header.Set("Last-Event-ID", "last")
+		rest.NewCatalogServer(productLister, rest.WithStream(broker, time.Second)).ServeHTTP(response, req)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
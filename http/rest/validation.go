@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/gorilla/mux"
+)
+
+// validateRequests rejects requests whose query parameters do not match the
+// OpenAPI operation of the matched route.
+func (cs *CatalogServer) validateRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		path, _ := route.GetPathTemplate()
+		if op := cs.operation(path, r.Method); op != nil {
+			if err := validateQuery(op, r); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validateQuery(op *operation, r *http.Request) error {
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		if p.In != "query" {
+			continue
+		}
+		value := query.Get(p.Name)
+		if value == "" {
+			if p.Required {
+				return catalog.NewInvalidArgumentError("%s is required", p.Name)
+			}
+			continue
+		}
+		if err := validateValue(p.Name, value, p.Schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateValue(name, value string, s *schema) error {
+	switch s.Type {
+	case "integer":
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return catalog.NewInvalidArgumentError("%s must be an integer, got %q", name, value)
+		}
+		if s.Minimum != nil && i < *s.Minimum {
+			if *s.Minimum == 0 {
+				return catalog.NewInvalidArgumentError("%s must not be negative, got %d", name, i)
+			}
+			return catalog.NewInvalidArgumentError("%s must be greater than %d, got %d", name, *s.Minimum-1, i)
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return catalog.NewInvalidArgumentError("%s must be a boolean, got %q", name, value)
+		}
+	case "string":
+		if len(s.Enum) == 0 {
+			return nil
+		}
+		// enum values are matched ignoring case, as the handlers parse them.
+		for _, e := range s.Enum {
+			if strings.EqualFold(e, value) {
+				return nil
+			}
+		}
+		return catalog.NewInvalidArgumentError("%s must be one of %s, got %q", name, strings.Join(s.Enum, ", "), value)
+	}
+	return nil
+}
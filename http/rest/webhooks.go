@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/webhooks"
+	"github.com/gorilla/mux"
+)
+
+// WithWebhooks serves the webhook subscriptions at /webhooks, to admins.
+func WithWebhooks(s *webhooks.Service) Option {
+	return func(cs *CatalogServer) {
+		cs.webhooks = s
+	}
+}
+
+type registration struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+func (cs *CatalogServer) registerWebhook(w http.ResponseWriter, r *http.Request) {
+	var body registration
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, r, catalog.NewInvalidArgumentError("invalid request body %v", err))
+		return
+	}
+	sub, err := cs.webhooks.Register(r.Context(), body.URL, body.Secret)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(sub)
+}
+
+func (cs *CatalogServer) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := cs.webhooks.Subscriptions(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if subs == nil {
+		subs = []webhooks.Subscription{}
+	}
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(subs)
+}
+
+func (cs *CatalogServer) unregisterWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := cs.webhooks.Unregister(r.Context(), mux.Vars(r)["id"]); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cs *CatalogServer) webhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	letters, err := cs.webhooks.DeadLetters(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if letters == nil {
+		letters = []webhooks.DeadLetter{}
+	}
+	w.Header().Set("content-type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(letters)
+}
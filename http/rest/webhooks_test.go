@@ -0,0 +1,104 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogServer_webhooks(t *testing.T) {
+	productLister := listing.NewProductLister(inmem.NewProductRepo(nil), pricingacl.NewCalculater(inmem.NewDiscountRepo(nil)))
+	service := webhooks.NewService(inmem.NewWebhookRepo(), productLister)
+	cs := rest.NewCatalogServer(productLister, rest.WithWebhooks(service), rest.WithAdminToken("s3cr3t"))
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cr3t")
+		cs.ServeHTTP(response, req)
+		return response
+	}
+
+	response := serve(http.MethodPost, "/webhooks", `{"url":"https://partner.example/prices","secret":"s3cr3t"}`)
+	require.Equal(t, http.StatusCreated, response.Code)
+	var sub webhooks.Subscription
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&sub))
+	assert.Equal(t, "https://partner.example/prices", sub.URL)
+	assert.Equal(t, "s3cr3t", sub.Secret)
+
+	tests := map[string]struct {
+		method string
+		target string
+		body   string
+		status int
+		want   string
+	}{
+		"Invalid URL": {
+			method: http.MethodPost,
+			target: "/webhooks",
+			body:   `{"url":"partner.example"}`,
+			status: http.StatusBadRequest,
+			want:   `{"error":{"code":"invalid_argument","message":"url must be an absolute http or https URL, got \"partner.example\""}}`,
+		},
+		"List without secrets": {
+			method: http.MethodGet,
+			target: "/webhooks",
+			status: http.StatusOK,
+			want:   `[{"id":"` + sub.ID + `","url":"https://partner.example/prices","created_at":"` + sub.CreatedAt.Format("2006-01-02T15:04:05.999999999Z07:00") + `"}]`,
+		},
+		"No dead letters": {
+			method: http.MethodGet,
+			target: "/webhooks/dead-letters",
+			status: http.StatusOK,
+			want:   `[]`,
+		},
+		"Unknown subscription": {
+			method: http.MethodDelete,
+			target: "/webhooks/unknown",
+			status: http.StatusNotFound,
+			want:   `{"error":{"code":"not_found","message":"webhook subscription not found"}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			response := serve(tt.method, tt.target, tt.body)
+
+			assert.Equal(t, tt.status, response.Code)
+			assert.JSONEq(t, tt.want, response.Body.String())
+		})
+	}
+
+	t.Run("Without admin token", func(t *testing.T) {
+		for _, route := range [][2]string{
+			{http.MethodPost, "/webhooks"},
+			{http.MethodGet, "/webhooks"},
+			{http.MethodGet, "/webhooks/dead-letters"},
+			{http.MethodDelete, "/webhooks/" + sub.ID},
+		} {
+			response := httptest.NewRecorder()
+			cs.ServeHTTP(response, httptest.NewRequest(route[0], route[1], strings.NewReader(`{"url":"https://attacker.example"}`)))
+
+			assert.Equal(t, http.StatusUnauthorized, response.Code, route)
+			assert.Equal(t, `Bearer realm="catalog"`, response.Header().Get("WWW-Authenticate"))
+		}
+		subs, _ := service.Subscriptions(context.Background())
+		assert.Len(t, subs, 1)
+	})
+
+	t.Run("Unregister", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/webhooks/"+sub.ID, "").Code)
+		assert.JSONEq(t, `[]`, serve(http.MethodGet, "/webhooks", "").Body.String())
+	})
+}
@@ -0,0 +1,136 @@
+package importing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+type Format string
+
+const (
+	CSVFormat  = Format("csv")
+	JSONFormat = Format("json")
+)
+
+var ErrUnknownFormat = catalog.NewInvalidArgumentError("unknown import format")
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case CSVFormat, JSONFormat:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownFormat, s)
+}
+
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+type record struct {
+	line   int
+	fields map[string]string
+}
+
+func (r record) get(name string) string {
+	return strings.TrimSpace(r.fields[name])
+}
+
+func decode(r io.Reader, f Format) ([]record, error) {
+	switch f {
+	case CSVFormat:
+		return decodeCSV(r)
+	case JSONFormat:
+		return decodeJSON(r)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, f)
+}
+
+func decodeCSV(r io.Reader) ([]record, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	var records []record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		fields := make(map[string]string, len(header))
+		for i, name := range header {
+			fields[name] = row[i]
+		}
+		records = append(records, record{line, fields})
+	}
+}
+
+func decodeJSON(r io.Reader) ([]record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+		return nil, &LineError{lineAt(data, 0), errors.New("expected a JSON array")}
+	}
+
+	var records []record
+	for dec.More() {
+		line := lineAt(data, dec.InputOffset())
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err != nil {
+			return nil, &LineError{line, err}
+		}
+		fields := make(map[string]string, len(obj))
+		for name, value := range obj {
+			fields[strings.ToLower(name)] = jsonString(value)
+		}
+		records = append(records, record{line, fields})
+	}
+	return records, nil
+}
+
+// lineAt returns the line of the first significant character at or after offset.
+func lineAt(data []byte, offset int64) int {
+	i := int(offset)
+	for i < len(data) && strings.ContainsRune(" \t\r\n,", rune(data[i])) {
+		i++
+	}
+	return bytes.Count(data[:i], []byte("\n")) + 1
+}
+
+func jsonString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,69 @@
+package importing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	. "github.com/amelendres/go-catalog/catalog"
+)
+
+const (
+	productDiscountType  = "product"
+	categoryDiscountType = "category"
+)
+
+func parseProduct(rec record) (*Product, error) {
+	sku := rec.get("sku")
+	if sku == "" {
+		return nil, errors.New("sku is required")
+	}
+	name := rec.get("name")
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	category := rec.get("category")
+	if category == "" {
+		return nil, errors.New("category is required")
+	}
+	price, err := strconv.Atoi(rec.get("price"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid price %q", rec.get("price"))
+	}
+	if price < 0 {
+		return nil, fmt.Errorf("price must not be negative, got %d", price)
+	}
+
+	return NewProduct(SKU(sku), name, Category(category), Price(price)), nil
+}
+
+func parseDiscount(rec record) (Discount, string, error) {
+	target := rec.get("target")
+	if target == "" {
+		return nil, "", errors.New("target is required")
+	}
+	percentage, err := strconv.Atoi(rec.get("percentage"))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid percentage %q", rec.get("percentage"))
+	}
+	if percentage <= 0 || percentage > 100 {
+		return nil, "", fmt.Errorf("percentage must be between 1 and 100, got %d", percentage)
+	}
+
+	dp := DiscountPercentage(percentage)
+	audience := PricingContext{Segment: rec.get("segment"), Channel: rec.get("channel"), Country: rec.get("country")}
+	key := rec.get("type") + ":" + target
+	for _, column := range []string{"segment", "channel", "country"} {
+		if v := rec.get(column); v != "" {
+			key += " " + column + ":" + v
+		}
+	}
+	switch t := rec.get("type"); t {
+	case productDiscountType:
+		return NewProductDiscount(SKU(target), dp, ForAudience(audience)), key, nil
+	case categoryDiscountType:
+		return NewCategoryDiscount(Category(target), dp, ForAudience(audience)), key, nil
+	default:
+		return nil, "", fmt.Errorf("unknown discount type %q", t)
+	}
+}
@@ -0,0 +1,148 @@
+package importing
+
+import (
+	"fmt"
+	"io"
+
+	. "github.com/amelendres/go-catalog/catalog"
+)
+
+type Mode string
+
+const (
+	UpsertMode  = Mode("upsert")
+	ReplaceMode = Mode("replace")
+)
+
+var (
+	ErrInvalidRows = NewInvalidArgumentError("invalid rows, nothing was imported")
+	ErrUnknownMode = NewInvalidArgumentError("unknown import mode")
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case UpsertMode, ReplaceMode:
+		return m, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownMode, s)
+}
+
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+type Report struct {
+	Imported int
+	Errors   []*LineError
+}
+
+func (r *Report) addError(line int, err error) {
+	r.Errors = append(r.Errors, &LineError{line, err})
+}
+
+type Importer interface {
+	ImportProducts(r io.Reader, f Format, m Mode) (*Report, error)
+	ImportDiscounts(r io.Reader, f Format, m Mode) (*Report, error)
+}
+
+type service struct {
+	products  ProductStore
+	discounts DiscountStore
+}
+
+func NewImporter(ps ProductStore, ds DiscountStore) Importer {
+	return &service{ps, ds}
+}
+
+func (s service) ImportProducts(r io.Reader, f Format, m Mode) (*Report, error) {
+	records, err := decode(r, f)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	seen := make(map[SKU]int)
+	var products []*Product
+	for _, rec := range records {
+		p, err := parseProduct(rec)
+		if err != nil {
+			report.addError(rec.line, err)
+			continue
+		}
+		if line, ok := seen[p.SKU]; ok {
+			report.addError(rec.line, fmt.Errorf("duplicated sku %q, first seen at line %d", p.SKU, line))
+			continue
+		}
+		seen[p.SKU] = rec.line
+		products = append(products, p)
+	}
+	if len(report.Errors) > 0 {
+		return report, ErrInvalidRows
+	}
+
+	switch m {
+	case ReplaceMode:
+		err = s.products.ReplaceAll(products)
+	case UpsertMode:
+		err = s.products.Save(products)
+	default:
+		err = fmt.Errorf("%w %q", ErrUnknownMode, m)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.Imported = len(products)
+	return report, nil
+}
+
+func (s service) ImportDiscounts(r io.Reader, f Format, m Mode) (*Report, error) {
+	records, err := decode(r, f)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	seen := make(map[string]int)
+	var discounts []Discount
+	for _, rec := range records {
+		d, key, err := parseDiscount(rec)
+		if err != nil {
+			report.addError(rec.line, err)
+			continue
+		}
+		if line, ok := seen[key]; ok {
+			report.addError(rec.line, fmt.Errorf("duplicated discount %s, first seen at line %d", key, line))
+			continue
+		}
+		seen[key] = rec.line
+		discounts = append(discounts, d)
+	}
+	if len(report.Errors) > 0 {
+		return report, ErrInvalidRows
+	}
+
+	switch m {
+	case ReplaceMode:
+		err = s.discounts.ReplaceAll(discounts)
+	case UpsertMode:
+		err = s.discounts.Save(discounts)
+	default:
+		err = fmt.Errorf("%w %q", ErrUnknownMode, m)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.Imported = len(discounts)
+	return report, nil
+}
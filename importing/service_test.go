@@ -0,0 +1,183 @@
+package importing_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/importing"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	givenProductsCSV = `sku,name,category,price
+000001,BV Lean leather ankle boots,boots,89000
+000007,Miu Miu sandals,sandals,45000
+`
+	givenInvalidProductsCSV = `sku,name,category,price
+000001,BV Lean leather ankle boots,boots,89000
+,Nameless,boots,1000
+000008,Cheap boots,boots,-10
+000001,Duplicated boots,boots,1000
+`
+	givenProductsJSON = `[
+	{"sku": "000001", "name": "BV Lean leather ankle boots", "category": "boots", "price": 89000},
+	{"sku": "000007", "name": "Miu Miu sandals", "category": "sandals", "price": 45000}
+]`
+	givenInvalidProductsJSON = `[
+	{"sku": "000001", "name": "BV Lean leather ankle boots", "category": "boots", "price": 89000},
+	{"sku": "000007", "name": "Miu Miu sandals", "category": "sandals", "price": "cheap"}
+]`
+	givenDiscountsCSV = `type,target,percentage
+category,sandals,20
+product,000001,10
+`
+	givenScopedDiscountsCSV = `type,target,percentage,segment,channel,country
+category,sandals,20,,,
+category,sandals,30,b2b,,
+product,000001,10,,mobile,ES
+`
+	givenDuplicatedScopedDiscountsCSV = `type,target,percentage,segment,channel,country
+category,sandals,30,b2b,,
+category,sandals,35,b2b,,
+`
+	givenInvalidDiscountsCSV = `type,target,percentage
+season,summer,20
+product,000001,120
+`
+)
+
+var givenProducts = []*catalog.Product{
+	catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+	catalog.NewProduct("000002", "BV Lean leather ankle boots", "boots", 99000),
+}
+
+func TestImporter_ImportProducts(t *testing.T) {
+	tests := map[string]struct {
+		data       string
+		format     importing.Format
+		mode       importing.Mode
+		want       *importing.Report
+		wantErr    error
+		wantLines  []int
+		wantTotals int
+	}{
+		"Upsert CSV": {
+			data:       givenProductsCSV,
+			format:     importing.CSVFormat,
+			mode:       importing.UpsertMode,
+			want:       &importing.Report{Imported: 2},
+			wantTotals: 3,
+		},
+		"Replace CSV": {
+			data:       givenProductsCSV,
+			format:     importing.CSVFormat,
+			mode:       importing.ReplaceMode,
+			want:       &importing.Report{Imported: 2},
+			wantTotals: 2,
+		},
+		"Upsert JSON": {
+			data:       givenProductsJSON,
+			format:     importing.JSONFormat,
+			mode:       importing.UpsertMode,
+			want:       &importing.Report{Imported: 2},
+			wantTotals: 3,
+		},
+		"Invalid CSV rows": {
+			data:       givenInvalidProductsCSV,
+			format:     importing.CSVFormat,
+			mode:       importing.ReplaceMode,
+			wantErr:    importing.ErrInvalidRows,
+			wantLines:  []int{3, 4, 5},
+			wantTotals: 2,
+		},
+		"Invalid JSON rows": {
+			data:       givenInvalidProductsJSON,
+			format:     importing.JSONFormat,
+			mode:       importing.UpsertMode,
+			wantErr:    importing.ErrInvalidRows,
+			wantLines:  []int{3},
+			wantTotals: 2,
+		},
+	}
+
+	for name, tc := range tests {
+		productRepo := inmem.NewProductRepo(append([]*catalog.Product(nil), givenProducts...))
+		importer := importing.NewImporter(productRepo, inmem.NewDiscountRepo(nil))
+
+		got, err := importer.ImportProducts(strings.NewReader(tc.data), tc.format, tc.mode)
+
+		all, _ := productRepo.List(context.Background(), catalog.NewSearchCriteria(&catalog.Pagination{Limit: 10}, nil))
+		assert.Equal(t, tc.wantTotals, all.Meta.Total, name)
+
+		if tc.wantErr != nil {
+			assert.ErrorIs(t, err, tc.wantErr, name)
+			var lines []int
+			for _, e := range got.Errors {
+				lines = append(lines, e.Line)
+			}
+			assert.Equal(t, tc.wantLines, lines, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, tc.want, got, name)
+	}
+}
+
+func TestImporter_ImportDiscounts(t *testing.T) {
+	tests := map[string]struct {
+		data      string
+		want      *importing.Report
+		wantFound int
+		wantErr   error
+		wantLines []int
+	}{
+		"Valid CSV": {
+			data:      givenDiscountsCSV,
+			want:      &importing.Report{Imported: 2},
+			wantFound: 2,
+		},
+		"Scoped CSV": {
+			data:      givenScopedDiscountsCSV,
+			want:      &importing.Report{Imported: 3},
+			wantFound: 3,
+		},
+		"Duplicated scoped CSV rows": {
+			data:      givenDuplicatedScopedDiscountsCSV,
+			wantErr:   importing.ErrInvalidRows,
+			wantLines: []int{3},
+		},
+		"Invalid CSV rows": {
+			data:      givenInvalidDiscountsCSV,
+			wantErr:   importing.ErrInvalidRows,
+			wantLines: []int{2, 3},
+		},
+	}
+
+	for name, tc := range tests {
+		discountRepo := inmem.NewDiscountRepo(nil)
+		importer := importing.NewImporter(inmem.NewProductRepo(nil), discountRepo)
+
+		got, err := importer.ImportDiscounts(strings.NewReader(tc.data), importing.CSVFormat, importing.UpsertMode)
+
+		if tc.wantErr != nil {
+			assert.ErrorIs(t, err, tc.wantErr, name)
+			var lines []int
+			for _, e := range got.Errors {
+				lines = append(lines, e.Line)
+			}
+			assert.Equal(t, tc.wantLines, lines, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, tc.want, got, name)
+
+		discounts, _ := discountRepo.Find(context.Background(), catalog.NewSearchCriteria(nil, []catalog.Filter{
+			catalog.NewCategoryFilter("sandals"),
+			catalog.NewSKUFilter("000001"),
+		}))
+		assert.Len(t, discounts, tc.wantFound, name)
+	}
+}
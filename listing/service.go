@@ -1,34 +1,40 @@
 package listing
 
 import (
+	"context"
+
 	. "github.com/amelendres/go-catalog/catalog"
-	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/logging"
+	"go.uber.org/zap"
 )
 
+const listAllPageSize = 100
+
 type ProductLister interface {
-	List(search SearchCriteria) (*PaginatedDiscountedProducts, error)
+	List(ctx context.Context, search SearchCriteria) (*PaginatedDiscountedProducts, error)
 }
 
 type service struct {
 	repository        ProductRepository
-	pricingCalculater pricing.Calculater
+	pricingCalculater Calculater
 }
 
-func NewProductLister(r ProductRepository, pc pricing.Calculater) ProductLister {
+func NewProductLister(r ProductRepository, pc Calculater) ProductLister {
 	return &service{r, pc}
 }
 
-func (s service) List(search SearchCriteria) (*PaginatedDiscountedProducts, error) {
+func (s service) List(ctx context.Context, search SearchCriteria) (*PaginatedDiscountedProducts, error) {
 
-	paginatedProducts, err := s.repository.List(search)
+	paginatedProducts, err := s.repository.List(ctx, search)
 	if err != nil {
+		logging.FromContext(ctx).Error("could not list products", zap.Error(err), logging.Criteria(search))
 		return nil, err
 	}
 
 	var discountedProducts []*DiscountedProduct
 	for _, p := range paginatedProducts.Items() {
 
-		price, err := s.pricingCalculater.Calculate(*p)
+		price, err := s.pricingCalculater.Calculate(ctx, *p)
 		if err != nil {
 			return nil, err
 		}
@@ -38,3 +44,39 @@ func (s service) List(search SearchCriteria) (*PaginatedDiscountedProducts, erro
 
 	return NewPaginatedDiscountedProducts(paginatedProducts.Meta, discountedProducts), nil
 }
+
+// GetProduct returns the discounted product with the given SKU.
+func GetProduct(ctx context.Context, pl ProductLister, sku SKU) (*DiscountedProduct, error) {
+	pag, err := NewPagination(1, 0)
+	if err != nil {
+		return nil, err
+	}
+	products, err := pl.List(ctx, NewSearchCriteria(pag, []Filter{NewSKUFilter(sku)}))
+	if err != nil {
+		return nil, err
+	}
+	if len(products.Items()) == 0 {
+		return nil, NewNotFoundError("product %q not found", sku)
+	}
+	return products.Items()[0], nil
+}
+
+// ListAll returns every discounted product matching the filters, listing them
+// page by page.
+func ListAll(ctx context.Context, pl ProductLister, filters []Filter) ([]*DiscountedProduct, error) {
+	var all []*DiscountedProduct
+	for offset := 0; ; offset += listAllPageSize {
+		pag, err := NewPagination(listAllPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		page, err := pl.List(ctx, NewSearchCriteria(pag, filters))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Items()...)
+		if len(page.Items()) < listAllPageSize {
+			return all, nil
+		}
+	}
+}
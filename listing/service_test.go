@@ -1,13 +1,13 @@
 package listing_test
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/amelendres/go-catalog/catalog"
 	"github.com/amelendres/go-catalog/listing"
-	"github.com/amelendres/go-catalog/pricing"
 	"github.com/amelendres/go-catalog/testing/stub"
 	"github.com/stretchr/testify/assert"
 )
@@ -36,7 +36,7 @@ type ProductRepoStub struct {
 	wantErr  error
 }
 
-func (r *ProductRepoStub) List(search catalog.SearchCriteria) (products *catalog.PaginatedProducts, err error) {
+func (r *ProductRepoStub) List(ctx context.Context, search catalog.SearchCriteria) (products *catalog.PaginatedProducts, err error) {
 	if r.wantErr != nil {
 		return nil, r.wantErr
 	}
@@ -57,11 +57,11 @@ func newStubPricingCalculater(
 	repo catalog.DiscountRepository,
 	prices map[string]*catalog.DiscountedPrice,
 	wantErr error,
-) pricing.Calculater {
+) catalog.Calculater {
 	return &StubPricingCalculater{repository: repo, discountedPrices: prices, wantErr: wantErr}
 }
 
-func (s StubPricingCalculater) Calculate(p catalog.Product) (*catalog.DiscountedPrice, error) {
+func (s StubPricingCalculater) Calculate(ctx context.Context, p catalog.Product) (*catalog.DiscountedPrice, error) {
 	if s.wantErr != nil {
 		return nil, s.wantErr
 	}
@@ -113,7 +113,7 @@ func TestProductLister_List(t *testing.T) {
 	}
 
 	for name, tc := range tests {
-		got, err := tc.in.List(tc.search)
+		got, err := tc.in.List(context.Background(), tc.search)
 
 		if tc.wantErr != nil {
 			assert.Error(t, err, name)
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"context"
+	"io"
+
+	. "github.com/amelendres/go-catalog/catalog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type loggerKey struct{}
+
+// New builds a JSON logger writing to w.
+func New(w io.Writer, level zapcore.Level) *zap.Logger {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = "time"
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(cfg), zapcore.AddSync(w), level)
+	return zap.New(core)
+}
+
+func WithLogger(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the request scoped logger, or a no-op one when there is none.
+func FromContext(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return zap.NewNop()
+}
+
+// Criteria logs a search criteria as a nested object.
+func Criteria(search SearchCriteria) zap.Field {
+	return zap.Object("criteria", criteria(search))
+}
+
+type criteria SearchCriteria
+
+func (c criteria) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	search := SearchCriteria(c)
+	if pag := search.Pagination(); pag != nil {
+		enc.AddInt("limit", pag.Limit)
+		enc.AddInt("offset", pag.Offset)
+	}
+	for _, f := range search.Filters() {
+		switch filter := f.(type) {
+		case CategoryFilter:
+			enc.AddString("category", string(filter.Value()))
+		case PriceLessThanFilter:
+			enc.AddInt("price_less_than", int(filter.Value()))
+		case SKUFilter:
+			enc.AddString("sku", string(filter.Value()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "catalog"
+
+type Registry struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	repositoryDuration *prometheus.HistogramVec
+	repositoryErrors   *prometheus.CounterVec
+
+	productsPriced   prometheus.Counter
+	discountsApplied *prometheus.CounterVec
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latencies by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "call_duration_seconds",
+			Help:      "Repository call latencies by repository and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"repository", "method"}),
+		repositoryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "errors_total",
+			Help:      "Repository call errors by repository and method.",
+		}, []string{"repository", "method"}),
+		productsPriced: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pricing",
+			Name:      "products_priced_total",
+			Help:      "Products priced by the pricing calculater.",
+		}),
+		discountsApplied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pricing",
+			Name:      "discounts_applied_total",
+			Help:      "Discounts applied by discount type.",
+		}, []string{"type"}),
+	}
+
+	r.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.httpRequests,
+		r.httpDuration,
+		r.repositoryDuration,
+		r.repositoryErrors,
+		r.productsPriced,
+		r.discountsApplied,
+	)
+	return r
+}
+
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
+
+func (r *Registry) Gatherer() prometheus.Gatherer {
+	return r.registry
+}
+
+func (r *Registry) ObserveRequest(route, method string, status int, d time.Duration) {
+	code := strconv.Itoa(status)
+	r.httpRequests.WithLabelValues(route, method, code).Inc()
+	r.httpDuration.WithLabelValues(route, method, code).Observe(d.Seconds())
+}
+
+func (r *Registry) observeRepositoryCall(repository, method string, start time.Time, err error) {
+	r.repositoryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		r.repositoryErrors.WithLabelValues(repository, method).Inc()
+	}
+}
+
+// Priced implements pricing.Observer, labelling the applied rules with the
+// catalog discount types they stand for.
+func (r *Registry) Priced(it pricing.Item, applied *pricing.Rule) {
+	r.productsPriced.Inc()
+	if applied == nil {
+		return
+	}
+	switch applied.Scope {
+	case pricing.ItemScope:
+		r.discountsApplied.WithLabelValues("product").Inc()
+	case pricing.GroupScope:
+		r.discountsApplied.WithLabelValues("category").Inc()
+	}
+}
@@ -0,0 +1,87 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/metrics"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/testing/stub"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var givenProducts = []*catalog.Product{
+	catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+	catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000),
+	catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+	catalog.NewProduct("000005", "Nathane leather sneakers", "sneakers", 59000),
+}
+
+func TestRegistry_CatalogServer(t *testing.T) {
+	registry := metrics.NewRegistry()
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{
+		catalog.NewCategoryDiscount("boots", 30),
+		catalog.NewProductDiscount("000005", 15),
+	})
+	pricingCalculater := pricingacl.NewCalculater(registry.DiscountRepository(discountRepo), pricing.WithObserver(registry))
+	productLister := listing.NewProductLister(registry.ProductRepository(inmem.NewProductRepo(givenProducts)), pricingCalculater)
+	catalogService := rest.NewCatalogServer(productLister, rest.WithMetrics(registry))
+
+	for _, target := range []string{"/products", "/products?limit=2", "/products?limit=Hi", "/unknown"} {
+		catalogService.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	expected := `
+# HELP catalog_http_requests_total HTTP requests by route, method and status.
+# TYPE catalog_http_requests_total counter
+catalog_http_requests_total{method="GET",route="/products",status="200"} 2
+catalog_http_requests_total{method="GET",route="/products",status="400"} 1
+catalog_http_requests_total{method="GET",route="unmatched",status="404"} 1
+# HELP catalog_pricing_discounts_applied_total Discounts applied by discount type.
+# TYPE catalog_pricing_discounts_applied_total counter
+catalog_pricing_discounts_applied_total{type="category"} 4
+catalog_pricing_discounts_applied_total{type="product"} 1
+# HELP catalog_pricing_products_priced_total Products priced by the pricing calculater.
+# TYPE catalog_pricing_products_priced_total counter
+catalog_pricing_products_priced_total 6
+`
+	err := testutil.GatherAndCompare(registry.Gatherer(), strings.NewReader(expected),
+		"catalog_http_requests_total",
+		"catalog_pricing_discounts_applied_total",
+		"catalog_pricing_products_priced_total",
+	)
+	assert.NoError(t, err)
+	count, err := testutil.GatherAndCount(registry.Gatherer(), "catalog_repository_call_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	response := httptest.NewRecorder()
+	catalogService.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, 200, response.Code)
+	assert.Contains(t, response.Body.String(), "catalog_repository_call_duration_seconds_count")
+}
+
+func TestRegistry_RepositoryErrors(t *testing.T) {
+	registry := metrics.NewRegistry()
+	discountRepo := registry.DiscountRepository(stub.NewStubDiscountRepo(nil, errors.New("fails discount repository")))
+
+	_, err := discountRepo.Find(context.Background(), catalog.SearchCriteria{})
+
+	assert.Error(t, err)
+	expected := `
+# HELP catalog_repository_errors_total Repository call errors by repository and method.
+# TYPE catalog_repository_errors_total counter
+catalog_repository_errors_total{method="find",repository="discounts"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry.Gatherer(), strings.NewReader(expected), "catalog_repository_errors_total"))
+}
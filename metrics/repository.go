@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	. "github.com/amelendres/go-catalog/catalog"
+)
+
+type productRepository struct {
+	next    ProductRepository
+	metrics *Registry
+}
+
+func (r *Registry) ProductRepository(next ProductRepository) ProductRepository {
+	return &productRepository{next, r}
+}
+
+func (r *productRepository) List(ctx context.Context, search SearchCriteria) (products *PaginatedProducts, err error) {
+	defer func(start time.Time) {
+		r.metrics.observeRepositoryCall("products", "list", start, err)
+	}(time.Now())
+	return r.next.List(ctx, search)
+}
+
+type discountRepository struct {
+	next    DiscountRepository
+	metrics *Registry
+}
+
+func (r *Registry) DiscountRepository(next DiscountRepository) DiscountRepository {
+	return &discountRepository{next, r}
+}
+
+func (r *discountRepository) Find(ctx context.Context, search SearchCriteria) (discounts []Discount, err error) {
+	defer func(start time.Time) {
+		r.metrics.observeRepositoryCall("discounts", "find", start, err)
+	}(time.Now())
+	return r.next.Find(ctx, search)
+}
@@ -0,0 +1,70 @@
+// Package money does exact arithmetic on amounts in minor units of their
+// currency, cents of euro, failing instead of overflowing and rounding
+// explicitly wherever a result falls between two minor units.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrOverflow   = errors.New("money: amount overflows")
+	ErrNegative   = errors.New("money: amount must not be negative")
+	ErrPercentage = errors.New("money: percentage must be between 0 and 100")
+)
+
+// Amount is a quantity of minor units of a currency.
+type Amount int64
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("%w: %d + %d", ErrOverflow, a, b)
+	}
+	return a + b, nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, fmt.Errorf("%w: %d - %d", ErrOverflow, a, b)
+	}
+	return a - b, nil
+}
+
+func (a Amount) Mul(n int64) (Amount, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	p := int64(a) * n
+	if p/n != int64(a) || (a == -1 && n == math.MinInt64) || (n == -1 && a == math.MinInt64) {
+		return 0, fmt.Errorf("%w: %d * %d", ErrOverflow, a, n)
+	}
+	return Amount(p), nil
+}
+
+// Discount takes percentage percent off a, rounding the discounted amount
+// with r. The result is never negative nor above a.
+func (a Amount) Discount(percentage int, r Rounding) (Amount, error) {
+	if a < 0 {
+		return 0, fmt.Errorf("%w, got %d", ErrNegative, a)
+	}
+	if percentage < 0 || percentage > 100 {
+		return 0, fmt.Errorf("%w, got %d", ErrPercentage, percentage)
+	}
+	// a*(100-percentage)/100 may overflow, splitting a in whole hundreds and
+	// the rest never does.
+	keep := int64(100 - percentage)
+	hundreds, rest := int64(a)/100, int64(a)%100
+	whole := hundreds*keep + rest*keep/100
+	hundredths := rest * keep % 100
+
+	discounted := Amount(r.round(whole, hundredths))
+	if discounted > a {
+		return a, nil
+	}
+	if discounted < 0 {
+		return 0, nil
+	}
+	return discounted, nil
+}
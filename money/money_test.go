@@ -0,0 +1,115 @@
+package money_test
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/amelendres/go-catalog/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmount_Discount(t *testing.T) {
+	tests := map[string]struct {
+		amount     money.Amount
+		percentage int
+		rounding   money.Rounding
+		want       money.Amount
+		wantErr    error
+	}{
+		"Exact":                     {amount: 89000, percentage: 30, rounding: money.HalfUp, want: 62300},
+		"Half up":                   {amount: 1050, percentage: 5, rounding: money.HalfUp, want: 998},
+		"Below half":                {amount: 999, percentage: 15, rounding: money.HalfUp, want: 849},
+		"Half even rounds down":     {amount: 10, percentage: 75, rounding: money.HalfEven, want: 2},
+		"Half even rounds up":       {amount: 30, percentage: 75, rounding: money.HalfEven, want: 8},
+		"Price point down":          {amount: 89000, percentage: 30, rounding: money.PricePoint, want: 62299},
+		"Price point up":            {amount: 12500, percentage: 50, rounding: money.PricePoint, want: 6299},
+		"Price point tie":           {amount: 12498, percentage: 50, rounding: money.PricePoint, want: 6199},
+		"Price point above amount":  {amount: 50, percentage: 10, rounding: money.PricePoint, want: 50},
+		"Price point keeps point":   {amount: 6299, percentage: 0, rounding: money.PricePoint, want: 6299},
+		"Whole discount":            {amount: 89000, percentage: 100, rounding: money.HalfUp, want: 0},
+		"No discount":               {amount: 89000, percentage: 0, rounding: money.HalfUp, want: 89000},
+		"Largest amount":            {amount: math.MaxInt64, percentage: 0, rounding: money.HalfUp, want: math.MaxInt64},
+		"Largest amount discounted": {amount: math.MaxInt64, percentage: 50, rounding: money.HalfEven, want: 4611686018427387904},
+		"Negative amount":           {amount: -100, percentage: 10, rounding: money.HalfUp, wantErr: money.ErrNegative},
+		"Percentage above 100":      {amount: 100, percentage: 101, rounding: money.HalfUp, wantErr: money.ErrPercentage},
+		"Negative percentage":       {amount: 100, percentage: -1, rounding: money.HalfUp, wantErr: money.ErrPercentage},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.amount.Discount(tc.percentage, tc.rounding)
+
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAmount_DiscountIsBounded(t *testing.T) {
+	roundings := []money.Rounding{money.HalfUp, money.HalfEven, money.PricePoint}
+	bounded := func(amount int64, percentage uint8, rounding uint8) bool {
+		if amount < 0 {
+			amount = -(amount + 1)
+		}
+		a, p, r := money.Amount(amount), int(percentage)%101, roundings[int(rounding)%len(roundings)]
+		got, err := a.Discount(p, r)
+		return err == nil && got >= 0 && got <= a
+	}
+
+	if err := quick.Check(bounded, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAmount_DiscountIsNearest(t *testing.T) {
+	// below 2^53 the float64 product is exact enough to tell the nearest cent.
+	nearest := func(amount uint32, percentage uint8) bool {
+		a, p := money.Amount(amount), int(percentage)%101
+		got, err := a.Discount(p, money.HalfUp)
+		exact := float64(amount) * float64(100-p) / 100
+		return err == nil && math.Abs(float64(got)-exact) <= 0.5
+	}
+
+	if err := quick.Check(nearest, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAmount_Overflow(t *testing.T) {
+	tests := map[string]struct {
+		op      func() (money.Amount, error)
+		want    money.Amount
+		wantErr error
+	}{
+		"Add":            {op: func() (money.Amount, error) { return money.Amount(1).Add(2) }, want: 3},
+		"Add overflows":  {op: func() (money.Amount, error) { return money.Amount(math.MaxInt64).Add(1) }, wantErr: money.ErrOverflow},
+		"Add underflows": {op: func() (money.Amount, error) { return money.Amount(math.MinInt64).Add(-1) }, wantErr: money.ErrOverflow},
+		"Sub":            {op: func() (money.Amount, error) { return money.Amount(1).Sub(2) }, want: -1},
+		"Sub overflows":  {op: func() (money.Amount, error) { return money.Amount(math.MaxInt64).Sub(-1) }, wantErr: money.ErrOverflow},
+		"Sub underflows": {op: func() (money.Amount, error) { return money.Amount(math.MinInt64).Sub(1) }, wantErr: money.ErrOverflow},
+		"Mul":            {op: func() (money.Amount, error) { return money.Amount(-3).Mul(4) }, want: -12},
+		"Mul overflows":  {op: func() (money.Amount, error) { return money.Amount(math.MaxInt64 / 2).Mul(3) }, wantErr: money.ErrOverflow},
+		"Mul min by -1":  {op: func() (money.Amount, error) { return money.Amount(math.MinInt64).Mul(-1) }, wantErr: money.ErrOverflow},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.op()
+
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	for _, name := range []string{"half-up", "half-even", "price-point"} {
+		r, err := money.ParseRounding(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, r.String())
+	}
+
+	_, err := money.ParseRounding("banker")
+	assert.Error(t, err)
+}
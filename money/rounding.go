@@ -0,0 +1,80 @@
+package money
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rounding tells how an exact amount falling between two minor units is
+// rounded to one of them.
+type Rounding int
+
+const (
+	// HalfUp rounds to the nearest minor unit, halves away from zero.
+	HalfUp Rounding = iota
+	// HalfEven rounds to the nearest minor unit, halves to the even one.
+	HalfEven
+	// PricePoint rounds to the nearest .99 price point, 62.30 to 61.99 and
+	// 62.50 to 62.99, halves down.
+	PricePoint
+)
+
+var roundings = map[string]Rounding{
+	"half-up":     HalfUp,
+	"half-even":   HalfEven,
+	"price-point": PricePoint,
+}
+
+func ParseRounding(name string) (Rounding, error) {
+	if r, ok := roundings[name]; ok {
+		return r, nil
+	}
+	return 0, fmt.Errorf("unknown rounding %q", name)
+}
+
+func (r Rounding) String() string {
+	for name, rounding := range roundings {
+		if rounding == r {
+			return name
+		}
+	}
+	return fmt.Sprintf("Rounding(%d)", int(r))
+}
+
+// round rounds the non-negative whole + hundredths/100 to a minor unit.
+func (r Rounding) round(whole, hundredths int64) int64 {
+	switch r {
+	case HalfEven:
+		if hundredths > 50 || (hundredths == 50 && whole%2 == 1) {
+			return whole + 1
+		}
+		return whole
+	case PricePoint:
+		return pricePoint(whole, hundredths)
+	default:
+		if hundredths >= 50 {
+			return whole + 1
+		}
+		return whole
+	}
+}
+
+// pricePoint picks the .99 price point nearest to whole + hundredths/100,
+// the lower one on ties.
+func pricePoint(whole, hundredths int64) int64 {
+	lower := whole - (whole%100+1)%100
+	if lower < 0 {
+		return 99
+	}
+	if whole > math.MaxInt64-100 {
+		return lower
+	}
+	upper := lower + 100
+	// distances in hundredths of a minor unit, less than 10000.
+	toLower := (whole-lower)*100 + hundredths
+	toUpper := (upper-whole)*100 - hundredths
+	if toUpper < toLower {
+		return upper
+	}
+	return lower
+}
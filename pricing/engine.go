@@ -0,0 +1,87 @@
+package pricing
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/money"
+)
+
+// Engine prices items with the rules of its source.
+type Engine interface {
+	Price(ctx context.Context, it Item) (*Result, error)
+}
+
+// RuleSource finds the rules that may apply to an item.
+type RuleSource interface {
+	Rules(ctx context.Context, it Item) ([]Rule, error)
+}
+
+// Observer is notified of every priced item with the applied rule, which is
+// nil when no rule applies.
+type Observer interface {
+	Priced(it Item, applied *Rule)
+}
+
+type engine struct {
+	rules     RuleSource
+	strategy  Strategy
+	rounding  money.Rounding
+	observers []Observer
+}
+
+type Option func(e *engine)
+
+func WithStrategy(st Strategy) Option {
+	return func(e *engine) {
+		e.strategy = st
+	}
+}
+
+// WithRounding sets how discounted amounts are rounded, half up by default.
+func WithRounding(r money.Rounding) Option {
+	return func(e *engine) {
+		e.rounding = r
+	}
+}
+
+func WithObserver(o Observer) Option {
+	return func(e *engine) {
+		e.observers = append(e.observers, o)
+	}
+}
+
+func NewEngine(rs RuleSource, opts ...Option) Engine {
+	e := engine{rules: rs, strategy: HighestDiscount, rounding: money.HalfUp}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e
+}
+
+func (e engine) Price(ctx context.Context, it Item) (*Result, error) {
+	rules, err := e.rules.Rules(ctx, it)
+	if err != nil {
+		return nil, err
+	}
+
+	var applicable []Rule
+	for _, r := range rules {
+		if r.Applies(it) {
+			applicable = append(applicable, r)
+		}
+	}
+	if applicable == nil {
+		e.notify(it, nil)
+		return newResult(it, nil, nil, e.rounding)
+	}
+
+	applied := e.strategy(applicable)
+	e.notify(it, &applied)
+	return newResult(it, &applied, explain(applicable, applied), e.rounding)
+}
+
+func (e engine) notify(it Item, applied *Rule) {
+	for _, o := range e.observers {
+		o.Priced(it, applied)
+	}
+}
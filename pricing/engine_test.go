@@ -0,0 +1,127 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amelendres/go-catalog/money"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubRules struct {
+	rules []pricing.Rule
+	err   error
+}
+
+func (s stubRules) Rules(ctx context.Context, it pricing.Item) ([]pricing.Rule, error) {
+	return s.rules, s.err
+}
+
+type recorder struct {
+	applied []*pricing.Rule
+}
+
+func (r *recorder) Priced(it pricing.Item, applied *pricing.Rule) {
+	r.applied = append(r.applied, applied)
+}
+
+func TestEngine_Price(t *testing.T) {
+	itemRule := pricing.Rule{Scope: pricing.ItemScope, Target: "000003", Percentage: 15}
+	groupRule := pricing.Rule{Scope: pricing.GroupScope, Target: "boots", Percentage: 30}
+	otherRule := pricing.Rule{Scope: pricing.GroupScope, Target: "sandals", Percentage: 50}
+	rules := stubRules{rules: []pricing.Rule{itemRule, groupRule, otherRule}}
+	boots := pricing.Item{ID: "000003", Group: "boots", Amount: 71000, Currency: "EUR"}
+	sneakers := pricing.Item{ID: "000005", Group: "sneakers", Amount: 59000, Currency: "EUR"}
+	b2bRule := pricing.Rule{Scope: pricing.GroupScope, Target: "boots", Percentage: 40, Audience: pricing.Audience{Segment: "b2b"}}
+	b2bBoots := pricing.Item{ID: "000003", Group: "boots", Amount: 71000, Currency: "EUR", Audience: pricing.Audience{Segment: "b2b", Channel: "mobile", Country: "ES"}}
+	rulesErr := errors.New("fails rule source")
+
+	tests := map[string]struct {
+		in      pricing.Engine
+		to      pricing.Item
+		want    *pricing.Result
+		wantErr error
+	}{
+		"Highest discount": {
+			in: pricing.NewEngine(rules),
+			to: boots,
+			want: &pricing.Result{Original: 71000, Final: 49700, Currency: "EUR", Applied: &groupRule, Candidates: []pricing.Candidate{
+				{Rule: itemRule, Reason: "lower percentage than the applied rule"},
+				{Rule: groupRule, Applied: true, Reason: "highest percentage"},
+			}},
+		},
+		"Item discount first": {
+			in: pricing.NewEngine(rules, pricing.WithStrategy(pricing.ItemDiscountFirst)),
+			to: boots,
+			want: &pricing.Result{Original: 71000, Final: 60350, Currency: "EUR", Applied: &itemRule, Candidates: []pricing.Candidate{
+				{Rule: itemRule, Applied: true, Reason: "item rules win over group rules"},
+				{Rule: groupRule, Reason: "group rule overridden by an item rule"},
+			}},
+		},
+		"Price point rounding": {
+			in: pricing.NewEngine(rules, pricing.WithRounding(money.PricePoint)),
+			to: boots,
+			want: &pricing.Result{Original: 71000, Final: 49699, Currency: "EUR", Applied: &groupRule, Candidates: []pricing.Candidate{
+				{Rule: itemRule, Reason: "lower percentage than the applied rule"},
+				{Rule: groupRule, Applied: true, Reason: "highest percentage"},
+			}},
+		},
+		"Negative amount": {
+			in:      pricing.NewEngine(rules),
+			to:      pricing.Item{ID: "000003", Group: "boots", Amount: -1},
+			wantErr: money.ErrNegative,
+		},
+		"Audience rule": {
+			in: pricing.NewEngine(stubRules{rules: []pricing.Rule{groupRule, b2bRule}}),
+			to: b2bBoots,
+			want: &pricing.Result{Original: 71000, Final: 42600, Currency: "EUR", Applied: &b2bRule, Candidates: []pricing.Candidate{
+				{Rule: groupRule, Reason: "lower percentage than the applied rule"},
+				{Rule: b2bRule, Applied: true, Reason: "highest percentage"},
+			}},
+		},
+		"Audience rule of another audience": {
+			in:   pricing.NewEngine(stubRules{rules: []pricing.Rule{b2bRule}}),
+			to:   pricing.Item{ID: "000003", Group: "boots", Amount: 71000, Currency: "EUR", Audience: pricing.Audience{Segment: "loyalty", Channel: "mobile"}},
+			want: &pricing.Result{Original: 71000, Final: 71000, Currency: "EUR"},
+		},
+		"No applicable rule": {
+			in:   pricing.NewEngine(rules),
+			to:   sneakers,
+			want: &pricing.Result{Original: 59000, Final: 59000, Currency: "EUR"},
+		},
+		"Rule source error": {
+			in:      pricing.NewEngine(stubRules{err: rulesErr}),
+			to:      boots,
+			wantErr: rulesErr,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.in.Price(context.Background(), tc.to)
+
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEngine_observer(t *testing.T) {
+	groupRule := pricing.Rule{Scope: pricing.GroupScope, Target: "boots", Percentage: 30}
+	r := &recorder{}
+	e := pricing.NewEngine(stubRules{rules: []pricing.Rule{groupRule}}, pricing.WithObserver(r))
+
+	_, _ = e.Price(context.Background(), pricing.Item{ID: "000001", Group: "boots", Amount: 89000})
+	_, _ = e.Price(context.Background(), pricing.Item{ID: "000004", Group: "sandals", Amount: 79500})
+
+	assert.Equal(t, []*pricing.Rule{&groupRule, nil}, r.applied)
+}
+
+func TestParseStrategy(t *testing.T) {
+	_, err := pricing.ParseStrategy("product-first")
+	assert.NoError(t, err)
+	_, err = pricing.ParseStrategy("lowest")
+	assert.Error(t, err)
+}
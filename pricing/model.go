@@ -0,0 +1,126 @@
+package pricing
+
+import (
+	"fmt"
+
+	"github.com/amelendres/go-catalog/money"
+)
+
+// Item is anything the pricing context prices: an amount in minor units of
+// its currency, identified by ID and belonging to a group that rules may
+// target as a whole, priced for an audience. Rule sources may add the rule
+// of its promo code.
+type Item struct {
+	ID        string `json:"id"`
+	Group     string `json:"group"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	PromoCode string `json:"promo_code,omitempty"`
+	Audience
+}
+
+// Audience is who an item is priced for, or who a rule is limited to, the
+// empty fields of a rule matching any.
+type Audience struct {
+	Segment string `json:"segment,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+// Includes reports whether the audience of an item is part of a.
+func (a Audience) Includes(of Audience) bool {
+	return (a.Segment == "" || a.Segment == of.Segment) &&
+		(a.Channel == "" || a.Channel == of.Channel) &&
+		(a.Country == "" || a.Country == of.Country)
+}
+
+// Scope is what a rule targets, a single item or every item of a group.
+type Scope string
+
+const (
+	ItemScope  = Scope("item")
+	GroupScope = Scope("group")
+)
+
+// Rule discounts a percentage of the amount of the items it targets, priced
+// for its audience. PromoCode is set on the rules of a promo code.
+type Rule struct {
+	Scope      Scope  `json:"scope"`
+	Target     string `json:"target"`
+	Percentage int    `json:"percentage"`
+	PromoCode  string `json:"promo_code,omitempty"`
+	Audience
+}
+
+func (r Rule) Applies(it Item) bool {
+	if !r.Audience.Includes(it.Audience) {
+		return false
+	}
+	switch r.Scope {
+	case ItemScope:
+		return r.Target == it.ID
+	case GroupScope:
+		return r.Target == it.Group
+	}
+	return false
+}
+
+// Candidate is a rule matching the priced item, with why it was applied or
+// not.
+type Candidate struct {
+	Rule    Rule   `json:"rule"`
+	Applied bool   `json:"applied"`
+	Reason  string `json:"reason"`
+}
+
+// Result is the price of an item, with the rule applied, if any, among the
+// candidates.
+type Result struct {
+	Original   int64       `json:"original"`
+	Final      int64       `json:"final"`
+	Currency   string      `json:"currency"`
+	Applied    *Rule       `json:"applied"`
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+func newResult(it Item, applied *Rule, candidates []Candidate, r money.Rounding) (*Result, error) {
+	final := money.Amount(it.Amount)
+	if applied != nil {
+		var err error
+		if final, err = final.Discount(applied.Percentage, r); err != nil {
+			return nil, fmt.Errorf("could not price item %s %w", it.ID, err)
+		}
+	}
+	return &Result{Original: it.Amount, Final: int64(final), Currency: it.Currency, Applied: applied, Candidates: candidates}, nil
+}
+
+// explain tells why applied was picked among the candidate rules.
+func explain(rules []Rule, applied Rule) []Candidate {
+	highest := true
+	for _, r := range rules {
+		if r.Percentage > applied.Percentage {
+			highest = false
+		}
+	}
+	candidates := make([]Candidate, 0, len(rules))
+	picked := false
+	for _, r := range rules {
+		c := Candidate{Rule: r}
+		switch {
+		case r == applied && !picked:
+			picked, c.Applied = true, true
+			c.Reason = "highest percentage"
+			if !highest {
+				c.Reason = "item rules win over group rules"
+			}
+		case r.Percentage < applied.Percentage:
+			c.Reason = "lower percentage than the applied rule"
+		case r.Percentage == applied.Percentage:
+			c.Reason = "same percentage as the applied rule"
+		default:
+			c.Reason = "group rule overridden by an item rule"
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
@@ -0,0 +1,43 @@
+package pricing
+
+import "fmt"
+
+// Strategy picks the rule to apply among the ones matching an item.
+type Strategy func(rules []Rule) Rule
+
+var strategies = map[string]Strategy{
+	"highest":       HighestDiscount,
+	"product-first": ItemDiscountFirst,
+}
+
+func ParseStrategy(name string) (Strategy, error) {
+	if s, ok := strategies[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown pricing strategy %q", name)
+}
+
+func HighestDiscount(rules []Rule) Rule {
+	var rule = rules[0]
+	for _, r := range rules {
+		if r.Percentage > rule.Percentage {
+			rule = r
+		}
+	}
+	return rule
+}
+
+// ItemDiscountFirst applies the highest item rule, falling back to the
+// highest group rule when the item has none.
+func ItemDiscountFirst(rules []Rule) Rule {
+	var itemRules []Rule
+	for _, r := range rules {
+		if r.Scope == ItemScope {
+			itemRules = append(itemRules, r)
+		}
+	}
+	if itemRules != nil {
+		return HighestDiscount(itemRules)
+	}
+	return HighestDiscount(rules)
+}
@@ -0,0 +1,209 @@
+package pricingacl_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/rpc"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/testing/stub"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	givenCategoryDiscount = catalog.DiscountPercentage(30)
+	givenProductDiscount  = catalog.DiscountPercentage(15)
+)
+
+// newRemote serves e on a gRPC pricing service and returns its remote adapter.
+func newRemote(t *testing.T, e pricing.Engine) catalog.Calculater {
+	t.Helper()
+	server := rpc.NewServer(rpc.NewCatalogServer(nil, nil))
+	rpc.RegisterPricing(server, rpc.NewPricingServer(e))
+	ln := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("fails dialing %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pricingacl.NewRemote(conn)
+}
+
+func TestCalculater_Calculate(t *testing.T) {
+	discounts := []catalog.Discount{catalog.NewProductDiscount("000003", givenProductDiscount), catalog.NewCategoryDiscount("boots", givenCategoryDiscount)}
+	bootsProduct := *catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000)
+	sandalsProduct := *catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500)
+	rules := pricingacl.NewRules(stub.NewStubDiscountRepo(discounts, nil))
+	rulesWithErr := pricingacl.NewRules(stub.NewStubDiscountRepo(nil, errors.New("fails discount repository")))
+
+	adapters := map[string]func(t *testing.T, e pricing.Engine) catalog.Calculater{
+		"Local": func(t *testing.T, e pricing.Engine) catalog.Calculater {
+			return pricingacl.NewLocal(e)
+		},
+		"Remote": newRemote,
+	}
+	tests := map[string]struct {
+		engine  pricing.Engine
+		to      catalog.Product
+		want    *catalog.DiscountedPrice
+		wantErr bool
+	}{
+		"With discount": {
+			engine: pricing.NewEngine(rules),
+			to:     bootsProduct,
+			want:   catalog.NewDiscountedPrice(bootsProduct.Price, &givenCategoryDiscount),
+		},
+		"With product discount first": {
+			engine: pricing.NewEngine(rules, pricing.WithStrategy(pricing.ItemDiscountFirst)),
+			to:     bootsProduct,
+			want:   catalog.NewDiscountedPrice(bootsProduct.Price, &givenProductDiscount),
+		},
+		"Without discount": {
+			engine: pricing.NewEngine(rules),
+			to:     sandalsProduct,
+			want:   catalog.NewDiscountedPrice(sandalsProduct.Price, nil),
+		},
+		"Discount repository error": {
+			engine:  pricing.NewEngine(rulesWithErr),
+			to:      bootsProduct,
+			wantErr: true,
+		},
+	}
+
+	for adapter, newCalculater := range adapters {
+		for name, tc := range tests {
+			t.Run(adapter+"/"+name, func(t *testing.T) {
+				got, err := newCalculater(t, tc.engine).Calculate(context.Background(), tc.to)
+
+				if tc.wantErr {
+					assert.Error(t, err)
+					assert.Nil(t, got)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			})
+		}
+	}
+}
+
+func TestNewCalculater(t *testing.T) {
+	c := pricingacl.NewCalculater(stub.NewStubDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", givenCategoryDiscount)}, nil))
+
+	got, err := c.Calculate(context.Background(), *catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000))
+
+	assert.NoError(t, err)
+	assert.Equal(t, catalog.NewDiscountedPrice(89000, &givenCategoryDiscount), got)
+}
+
+func TestCalculater_CalculateExplained(t *testing.T) {
+	discounts := []catalog.Discount{catalog.NewProductDiscount("000003", givenProductDiscount), catalog.NewCategoryDiscount("boots", givenCategoryDiscount)}
+	bootsProduct := *catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000)
+	engine := pricing.NewEngine(pricingacl.NewRules(stub.NewStubDiscountRepo(discounts, nil)))
+
+	adapters := map[string]catalog.Calculater{
+		"Local":  pricingacl.NewLocal(engine),
+		"Remote": newRemote(t, engine),
+	}
+	want := &catalog.PriceExplanation{Candidates: []catalog.DiscountCandidate{
+		{Source: "product", Target: "000003", Percentage: givenProductDiscount, Reason: "lower percentage than the applied rule"},
+		{Source: "category", Target: "boots", Percentage: givenCategoryDiscount, Applied: true, Reason: "highest percentage"},
+	}}
+
+	for name, c := range adapters {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.Calculate(context.Background(), bootsProduct)
+			assert.NoError(t, err)
+			assert.Nil(t, got.Explanation, "prices are explained on request")
+
+			got, err = c.Calculate(catalog.WithExplanation(context.Background()), bootsProduct)
+			assert.NoError(t, err)
+			assert.Equal(t, catalog.Price(49700), got.Final)
+			assert.Equal(t, want, got.Explanation)
+		})
+	}
+}
+
+func TestCalculater_CalculateForPricingContext(t *testing.T) {
+	mobile := catalog.PricingContext{Channel: "mobile"}
+	discounts := []catalog.Discount{catalog.NewCategoryDiscount("boots", givenProductDiscount), catalog.NewCategoryDiscount("boots", givenCategoryDiscount, catalog.ForAudience(mobile))}
+	bootsProduct := *catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000)
+	engine := pricing.NewEngine(pricingacl.NewRules(stub.NewStubDiscountRepo(discounts, nil)))
+
+	adapters := map[string]catalog.Calculater{
+		"Local":  pricingacl.NewLocal(engine),
+		"Remote": newRemote(t, engine),
+	}
+
+	for name, c := range adapters {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.Calculate(context.Background(), bootsProduct)
+			assert.NoError(t, err)
+			assert.Equal(t, catalog.NewDiscountedPrice(bootsProduct.Price, &givenProductDiscount), got)
+
+			ctx := catalog.WithExplanation(catalog.WithPricingContext(context.Background(), catalog.PricingContext{Segment: "loyalty", Channel: "mobile"}))
+			got, err = c.Calculate(ctx, bootsProduct)
+			assert.NoError(t, err)
+			assert.Equal(t, catalog.Price(49700), got.Final)
+			assert.Equal(t, []catalog.DiscountCandidate{
+				{Source: "category", Target: "boots", Percentage: givenProductDiscount, Reason: "lower percentage than the applied rule"},
+				{Source: "category", Target: "boots", Percentage: givenCategoryDiscount, Audience: &mobile, Applied: true, Reason: "highest percentage"},
+			}, got.Explanation.Candidates)
+		})
+	}
+}
+
+func TestCalculater_CalculateWithPromoCode(t *testing.T) {
+	discounts := []catalog.Discount{catalog.NewCategoryDiscount("boots", givenProductDiscount)}
+	bootsProduct := *catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000)
+	sandalsProduct := *catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500)
+	s := coupons.NewService(inmem.NewCouponRepo(coupons.Coupon{Code: "BOOTS30", Percentage: givenCategoryDiscount, Eligibility: coupons.Eligibility{Categories: []catalog.Category{"boots"}}}))
+	engine := pricing.NewEngine(pricingacl.NewCouponRules(pricingacl.NewRules(stub.NewStubDiscountRepo(discounts, nil)), s))
+
+	adapters := map[string]catalog.Calculater{
+		"Local":  pricingacl.NewLocal(engine),
+		"Remote": newRemote(t, engine),
+	}
+
+	for name, c := range adapters {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.Calculate(catalog.WithExplanation(catalog.WithPromoCode(context.Background(), "boots30")), bootsProduct)
+			assert.NoError(t, err)
+			assert.Equal(t, catalog.Price(49700), got.Final)
+			assert.Equal(t, "BOOTS30", got.PromoCode)
+			assert.Equal(t, []catalog.DiscountCandidate{
+				{Source: "category", Target: "boots", Percentage: givenProductDiscount, Reason: "lower percentage than the applied rule"},
+				{Source: "coupon", Target: "BOOTS30", Percentage: givenCategoryDiscount, Applied: true, Reason: "highest percentage"},
+			}, got.Explanation.Candidates)
+
+			got, err = c.Calculate(catalog.WithPromoCode(context.Background(), "BOOTS30"), sandalsProduct)
+			assert.NoError(t, err)
+			assert.Equal(t, catalog.NewDiscountedPrice(sandalsProduct.Price, nil), got, "ineligible products are priced without the code")
+
+			got, err = c.Calculate(catalog.WithPromoCode(context.Background(), "UNKNOWN"), bootsProduct)
+			assert.NoError(t, err)
+			assert.Equal(t, catalog.NewDiscountedPrice(bootsProduct.Price, &givenProductDiscount), got, "unknown codes are ignored")
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package pricingacl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/pricing"
+)
+
+type couponRules struct {
+	next    pricing.RuleSource
+	coupons *coupons.Service
+}
+
+// NewCouponRules adds to the rules of next the one of the promo code of the
+// item, when it applies. Unknown codes are ignored.
+func NewCouponRules(next pricing.RuleSource, s *coupons.Service) pricing.RuleSource {
+	return couponRules{next, s}
+}
+
+func (rs couponRules) Rules(ctx context.Context, it pricing.Item) ([]pricing.Rule, error) {
+	rules, err := rs.next.Rules(ctx, it)
+	if err != nil || it.PromoCode == "" {
+		return rules, err
+	}
+	p := catalog.NewProduct(catalog.SKU(it.ID), "", catalog.Category(it.Group), catalog.Price(it.Amount))
+	v, err := rs.coupons.Validate(ctx, it.PromoCode, p)
+	if errors.Is(err, catalog.ErrNotFound) {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !v.Applicable {
+		return rules, nil
+	}
+	return append(rules, pricing.Rule{Scope: pricing.ItemScope, Target: it.ID, Percentage: int(v.Percentage), PromoCode: v.Code}), nil
+}
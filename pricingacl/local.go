@@ -0,0 +1,31 @@
+package pricingacl
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/pricing"
+)
+
+type local struct {
+	engine pricing.Engine
+}
+
+// NewLocal prices the products with an in-process pricing engine.
+func NewLocal(e pricing.Engine) catalog.Calculater {
+	return local{e}
+}
+
+// NewCalculater prices the products in-process with the rules of the catalog
+// discounts.
+func NewCalculater(r catalog.DiscountRepository, opts ...pricing.Option) catalog.Calculater {
+	return NewLocal(pricing.NewEngine(NewRules(r), opts...))
+}
+
+func (l local) Calculate(ctx context.Context, p catalog.Product) (*catalog.DiscountedPrice, error) {
+	r, err := l.engine.Price(ctx, toItem(ctx, p))
+	if err != nil {
+		return nil, err
+	}
+	return toDiscountedPrice(ctx, r), nil
+}
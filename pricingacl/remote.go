@@ -0,0 +1,75 @@
+package pricingacl
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/rpc/pricingpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type remote struct {
+	client pricingpb.PricingServiceClient
+}
+
+// NewRemote prices the products with the pricing service served on conn.
+func NewRemote(conn grpc.ClientConnInterface) catalog.Calculater {
+	return remote{pricingpb.NewPricingServiceClient(conn)}
+}
+
+func (rm remote) Calculate(ctx context.Context, p catalog.Product) (*catalog.DiscountedPrice, error) {
+	it := toItem(ctx, p)
+	resp, err := rm.client.Price(ctx, &pricingpb.PriceRequest{Item: &pricingpb.Item{
+		Id:        it.ID,
+		Group:     it.Group,
+		Amount:    it.Amount,
+		Currency:  it.Currency,
+		PromoCode: it.PromoCode,
+		Audience:  toAudienceMessage(it.Audience),
+	}})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	r := resp.GetResult()
+	result := &pricing.Result{Original: r.GetOriginal(), Final: r.GetFinal(), Currency: r.GetCurrency()}
+	if a := r.GetApplied(); a != nil {
+		applied := fromRuleMessage(a)
+		result.Applied = &applied
+	}
+	for _, c := range r.GetCandidates() {
+		result.Candidates = append(result.Candidates, pricing.Candidate{Rule: fromRuleMessage(c.GetRule()), Applied: c.GetApplied(), Reason: c.GetReason()})
+	}
+	return toDiscountedPrice(ctx, result), nil
+}
+
+func fromRuleMessage(r *pricingpb.Rule) pricing.Rule {
+	return pricing.Rule{Scope: pricing.Scope(r.GetScope()), Target: r.GetTarget(), Percentage: int(r.GetPercentage()), PromoCode: r.GetPromoCode(), Audience: fromAudienceMessage(r.GetAudience())}
+}
+
+func toAudienceMessage(a pricing.Audience) *pricingpb.Audience {
+	if a == (pricing.Audience{}) {
+		return nil
+	}
+	return &pricingpb.Audience{Segment: a.Segment, Channel: a.Channel, Country: a.Country}
+}
+
+func fromAudienceMessage(a *pricingpb.Audience) pricing.Audience {
+	return pricing.Audience{Segment: a.GetSegment(), Channel: a.GetChannel(), Country: a.GetCountry()}
+}
+
+// fromStatus maps the pricing service statuses back to catalog errors.
+func fromStatus(err error) error {
+	s, _ := status.FromError(err)
+	switch s.Code() {
+	case codes.InvalidArgument:
+		return catalog.NewInvalidArgumentError("%s", s.Message())
+	case codes.NotFound:
+		return catalog.NewNotFoundError("%s", s.Message())
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return catalog.NewUnavailableError(err, "pricing service unavailable")
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package pricingacl
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/logging"
+	"github.com/amelendres/go-catalog/pricing"
+	"go.uber.org/zap"
+)
+
+type rules struct {
+	repository catalog.DiscountRepository
+}
+
+// NewRules sources the pricing rules from the catalog discounts.
+func NewRules(r catalog.DiscountRepository) pricing.RuleSource {
+	return rules{r}
+}
+
+func (rs rules) Rules(ctx context.Context, it pricing.Item) ([]pricing.Rule, error) {
+	criteria := catalog.NewSearchCriteria(nil, []catalog.Filter{
+		catalog.NewCategoryFilter(catalog.Category(it.Group)),
+		catalog.NewSKUFilter(catalog.SKU(it.ID)),
+	})
+	discounts, err := rs.repository.Find(ctx, criteria)
+	if err != nil {
+		logging.FromContext(ctx).Error("could not find discounts", zap.Error(err), logging.Criteria(criteria))
+		return nil, err
+	}
+	var rules []pricing.Rule
+	for _, d := range discounts {
+		rules = append(rules, ToRule(d))
+	}
+	return rules, nil
+}
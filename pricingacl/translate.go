@@ -0,0 +1,88 @@
+// Package pricingacl is the anti-corruption layer between the catalog and the
+// pricing context, translating products to priceable items, discounts to
+// pricing rules and price results back to discounted prices.
+package pricingacl
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/pricing"
+)
+
+// toItem prices p for the pricing context and with the promo code of ctx.
+func toItem(ctx context.Context, p catalog.Product) pricing.Item {
+	return pricing.Item{
+		ID:        string(p.SKU),
+		Group:     string(p.Category),
+		Amount:    int64(p.Price),
+		Currency:  string(catalog.EURCurrency),
+		PromoCode: catalog.PromoCodeFrom(ctx),
+		Audience:  toAudience(catalog.PricingContextFrom(ctx)),
+	}
+}
+
+func toAudience(pc catalog.PricingContext) pricing.Audience {
+	return pricing.Audience{Segment: pc.Segment, Channel: pc.Channel, Country: pc.Country}
+}
+
+// toPricingContext returns nil for the audience of the rules matching any.
+func toPricingContext(a pricing.Audience) *catalog.PricingContext {
+	if a == (pricing.Audience{}) {
+		return nil
+	}
+	return &catalog.PricingContext{Segment: a.Segment, Channel: a.Channel, Country: a.Country}
+}
+
+func toDiscountedPrice(ctx context.Context, r *pricing.Result) *catalog.DiscountedPrice {
+	price := &catalog.DiscountedPrice{Original: catalog.Price(r.Original), Final: catalog.Price(r.Final), Currenty: catalog.Currency(r.Currency)}
+	if r.Applied != nil {
+		dp := catalog.DiscountPercentage(r.Applied.Percentage)
+		price.DiscountPercentage = &dp
+		price.PromoCode = r.Applied.PromoCode
+	}
+	if catalog.ExplanationRequested(ctx) {
+		price.Explanation = toExplanation(r.Candidates)
+	}
+	return price
+}
+
+func toExplanation(candidates []pricing.Candidate) *catalog.PriceExplanation {
+	e := &catalog.PriceExplanation{Candidates: []catalog.DiscountCandidate{}}
+	for _, c := range candidates {
+		source, target := toSource(c.Rule)
+		e.Candidates = append(e.Candidates, catalog.DiscountCandidate{
+			Source:     source,
+			Target:     target,
+			Percentage: catalog.DiscountPercentage(c.Rule.Percentage),
+			Audience:   toPricingContext(c.Rule.Audience),
+			Applied:    c.Applied,
+			Reason:     c.Reason,
+		})
+	}
+	return e
+}
+
+// toSource returns where a rule comes from, the promo code being the target
+// of the rules of a coupon.
+func toSource(r pricing.Rule) (source, target string) {
+	switch {
+	case r.PromoCode != "":
+		return catalog.CouponSource, r.PromoCode
+	case r.Scope == pricing.ItemScope:
+		return catalog.ProductDiscountType, r.Target
+	}
+	return catalog.CategoryDiscountType, r.Target
+}
+
+// ToRule translates a catalog discount to the pricing rule it stands for.
+func ToRule(d catalog.Discount) pricing.Rule {
+	r := pricing.Rule{Percentage: int(d.Percentage()), Audience: toAudience(d.Audience())}
+	switch discount := d.(type) {
+	case *catalog.ProductDiscount:
+		r.Scope, r.Target = pricing.ItemScope, string(discount.SKU())
+	case *catalog.CategoryDiscount:
+		r.Scope, r.Target = pricing.GroupScope, string(discount.Category())
+	}
+	return r
+}
@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/logging"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errorCodes = map[catalog.ErrorCode]codes.Code{
+	catalog.NotFoundError:        codes.NotFound,
+	catalog.InvalidArgumentError: codes.InvalidArgument,
+	catalog.UnavailableError:     codes.Unavailable,
+	catalog.ConflictError:        codes.Aborted,
+	catalog.UnauthenticatedError: codes.Unauthenticated,
+	catalog.InternalError:        codes.Internal,
+}
+
+// toStatus maps a catalog error to a gRPC status, hiding the details of
+// internal and unavailable errors.
+func toStatus(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := catalog.ErrorCodeOf(err)
+	switch code {
+	case catalog.InternalError:
+		logging.FromContext(ctx).Error("rpc failed", zap.Error(err))
+		return status.Error(codes.Internal, "internal error")
+	case catalog.UnavailableError:
+		logging.FromContext(ctx).Error("rpc failed", zap.Error(err))
+		return status.Error(codes.Unavailable, "service unavailable")
+	}
+	c, ok := errorCodes[code]
+	if !ok {
+		c = codes.Internal
+	}
+	return status.Error(c, err.Error())
+}
+
+func errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/pricing"
+	"github.com/amelendres/go-catalog/rpc/pricingpb"
+	"google.golang.org/grpc"
+)
+
+// PricingServer serves the pricing context over gRPC.
+type PricingServer struct {
+	pricingpb.UnimplementedPricingServiceServer
+	engine pricing.Engine
+}
+
+func NewPricingServer(e pricing.Engine) *PricingServer {
+	return &PricingServer{engine: e}
+}
+
+// RegisterPricing serves ps on s, along with the catalog.
+func RegisterPricing(s *grpc.Server, ps *PricingServer) {
+	pricingpb.RegisterPricingServiceServer(s, ps)
+}
+
+func (ps *PricingServer) Price(ctx context.Context, req *pricingpb.PriceRequest) (*pricingpb.PriceResponse, error) {
+	it := req.GetItem()
+	if it.GetId() == "" {
+		return nil, catalog.NewInvalidArgumentError("item id is required")
+	}
+	if it.GetAmount() < 0 {
+		return nil, catalog.NewInvalidArgumentError("amount must not be negative, got %d", it.GetAmount())
+	}
+
+	r, err := ps.engine.Price(ctx, pricing.Item{
+		ID:        it.GetId(),
+		Group:     it.GetGroup(),
+		Amount:    it.GetAmount(),
+		Currency:  it.GetCurrency(),
+		PromoCode: it.GetPromoCode(),
+		Audience:  fromAudienceMessage(it.GetAudience()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &pricingpb.Result{Original: r.Original, Final: r.Final, Currency: r.Currency}
+	if r.Applied != nil {
+		result.Applied = toRuleMessage(*r.Applied)
+	}
+	for _, c := range r.Candidates {
+		result.Candidates = append(result.Candidates, &pricingpb.Candidate{Rule: toRuleMessage(c.Rule), Applied: c.Applied, Reason: c.Reason})
+	}
+	return &pricingpb.PriceResponse{Result: result}, nil
+}
+
+func toRuleMessage(r pricing.Rule) *pricingpb.Rule {
+	return &pricingpb.Rule{Scope: string(r.Scope), Target: r.Target, Percentage: int32(r.Percentage), PromoCode: r.PromoCode, Audience: toAudienceMessage(r.Audience)}
+}
+
+func toAudienceMessage(a pricing.Audience) *pricingpb.Audience {
+	if a == (pricing.Audience{}) {
+		return nil
+	}
+	return &pricingpb.Audience{Segment: a.Segment, Channel: a.Channel, Country: a.Country}
+}
+
+func fromAudienceMessage(a *pricingpb.Audience) pricing.Audience {
+	return pricing.Audience{Segment: a.GetSegment(), Channel: a.GetChannel(), Country: a.GetCountry()}
+}
@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/rpc/catalogpb"
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultLimit = 5
+	maxLimit     = 100
+)
+
+type CatalogServer struct {
+	catalogpb.UnimplementedCatalogServiceServer
+	productLister     listing.ProductLister
+	pricingCalculater catalog.Calculater
+	defaultLimit      int
+	maxLimit          int
+}
+
+type Option func(cs *CatalogServer)
+
+func WithPageSize(defaultLimit, maxLimit int) Option {
+	return func(cs *CatalogServer) {
+		cs.defaultLimit = defaultLimit
+		cs.maxLimit = maxLimit
+	}
+}
+
+func NewCatalogServer(pl listing.ProductLister, pc catalog.Calculater, opts ...Option) *CatalogServer {
+	cs := &CatalogServer{productLister: pl, pricingCalculater: pc, defaultLimit: defaultLimit, maxLimit: maxLimit}
+	for _, opt := range opts {
+		opt(cs)
+	}
+	return cs
+}
+
+// NewServer builds a gRPC server serving cs, mapping catalog errors to gRPC statuses.
+func NewServer(cs *CatalogServer, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(errorInterceptor))
+	s := grpc.NewServer(opts...)
+	catalogpb.RegisterCatalogServiceServer(s, cs)
+	return s
+}
+
+func (cs *CatalogServer) ListProducts(ctx context.Context, req *catalogpb.ListProductsRequest) (*catalogpb.ListProductsResponse, error) {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = cs.defaultLimit
+	}
+	if limit > cs.maxLimit {
+		limit = cs.maxLimit
+	}
+	pag, err := catalog.NewPagination(limit, int(req.GetOffset()))
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []catalog.Filter
+	if req.GetCategory() != "" {
+		filters = append(filters, catalog.NewCategoryFilter(catalog.Category(req.GetCategory())))
+	}
+	if req.PriceLessThan != nil {
+		filters = append(filters, catalog.NewPriceLessThanFilter(catalog.Price(req.GetPriceLessThan())))
+	}
+
+	products, err := cs.productLister.List(ctx, catalog.NewSearchCriteria(pag, filters))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &catalogpb.ListProductsResponse{Meta: &catalogpb.PaginationMeta{
+		Total:  int32(products.Meta.Total),
+		Limit:  int32(products.Meta.Limit),
+		Offset: int32(products.Meta.Offset),
+	}}
+	for _, p := range products.Items() {
+		resp.Items = append(resp.Items, toProductMessage(p))
+	}
+	return resp, nil
+}
+
+func (cs *CatalogServer) GetProduct(ctx context.Context, req *catalogpb.GetProductRequest) (*catalogpb.GetProductResponse, error) {
+	if req.GetSku() == "" {
+		return nil, catalog.NewInvalidArgumentError("sku is required")
+	}
+	p, err := listing.GetProduct(ctx, cs.productLister, catalog.SKU(req.GetSku()))
+	if err != nil {
+		return nil, err
+	}
+	return &catalogpb.GetProductResponse{Product: toProductMessage(p)}, nil
+}
+
+func (cs *CatalogServer) CalculatePrice(ctx context.Context, req *catalogpb.CalculatePriceRequest) (*catalogpb.CalculatePriceResponse, error) {
+	if req.GetSku() == "" {
+		return nil, catalog.NewInvalidArgumentError("sku is required")
+	}
+	if req.GetPrice() < 0 {
+		return nil, catalog.NewInvalidArgumentError("price must not be negative, got %d", req.GetPrice())
+	}
+
+	p := catalog.NewProduct(catalog.SKU(req.GetSku()), "", catalog.Category(req.GetCategory()), catalog.Price(req.GetPrice()))
+	price, err := cs.pricingCalculater.Calculate(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return &catalogpb.CalculatePriceResponse{Price: toPriceMessage(price)}, nil
+}
+
+func toProductMessage(p *catalog.DiscountedProduct) *catalogpb.DiscountedProduct {
+	return &catalogpb.DiscountedProduct{
+		Sku:      string(p.SKU),
+		Name:     p.Name,
+		Category: string(p.Category),
+		Price:    toPriceMessage(&p.Price),
+	}
+}
+
+func toPriceMessage(p *catalog.DiscountedPrice) *catalogpb.DiscountedPrice {
+	msg := &catalogpb.DiscountedPrice{
+		Original: int64(p.Original),
+		Final:    int64(p.Final),
+		Currency: string(p.Currenty),
+	}
+	if p.DiscountPercentage != nil {
+		dp := int32(*p.DiscountPercentage)
+		msg.DiscountPercentage = &dp
+	}
+	if p.Lowest30DaysPrice != nil {
+		lowest := int64(*p.Lowest30DaysPrice)
+		msg.Lowest_30DaysPrice = &lowest
+	}
+	return msg
+}
@@ -0,0 +1,174 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/rpc"
+	"github.com/amelendres/go-catalog/rpc/catalogpb"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	givenProducts = []*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+		catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000),
+		catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+	}
+	givenDiscounts = []catalog.Discount{
+		catalog.NewCategoryDiscount("boots", 30),
+		catalog.NewProductDiscount("000003", 15),
+	}
+)
+
+func newClient(t *testing.T) catalogpb.CatalogServiceClient {
+	pricingCalculater := pricingacl.NewCalculater(inmem.NewDiscountRepo(givenDiscounts))
+	productLister := listing.NewProductLister(inmem.NewProductRepo(givenProducts), pricingCalculater)
+	server := rpc.NewServer(rpc.NewCatalogServer(productLister, pricingCalculater))
+
+	ln := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("fails dialing %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return catalogpb.NewCatalogServiceClient(conn)
+}
+
+func percentage(p int32) *int32 {
+	return &p
+}
+
+func price(p int64) *int64 {
+	return &p
+}
+
+func TestCatalogServer_ListProducts(t *testing.T) {
+	client := newClient(t)
+
+	tests := map[string]struct {
+		req      *catalogpb.ListProductsRequest
+		wantSKUs []string
+		wantMeta *catalogpb.PaginationMeta
+		wantCode codes.Code
+	}{
+		"Default page": {
+			req:      &catalogpb.ListProductsRequest{},
+			wantSKUs: []string{"000001", "000003", "000004"},
+			wantMeta: &catalogpb.PaginationMeta{Total: 3, Limit: 5, Offset: 0},
+		},
+		"Second page": {
+			req:      &catalogpb.ListProductsRequest{Limit: 2, Offset: 2},
+			wantSKUs: []string{"000004"},
+			wantMeta: &catalogpb.PaginationMeta{Total: 3, Limit: 2, Offset: 2},
+		},
+		"Boots less than 80000": {
+			req:      &catalogpb.ListProductsRequest{Category: "boots", PriceLessThan: price(80000)},
+			wantSKUs: []string{"000003"},
+			wantMeta: &catalogpb.PaginationMeta{Total: 1, Limit: 5, Offset: 0},
+		},
+		"Negative offset": {
+			req:      &catalogpb.ListProductsRequest{Offset: -1},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := client.ListProducts(context.Background(), tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				return
+			}
+			var skus []string
+			for _, p := range resp.Items {
+				skus = append(skus, p.Sku)
+			}
+			assert.Equal(t, tt.wantSKUs, skus)
+			assert.Equal(t, tt.wantMeta.Total, resp.Meta.Total)
+			assert.Equal(t, tt.wantMeta.Limit, resp.Meta.Limit)
+			assert.Equal(t, tt.wantMeta.Offset, resp.Meta.Offset)
+		})
+	}
+}
+
+func TestCatalogServer_GetProduct(t *testing.T) {
+	client := newClient(t)
+
+	tests := map[string]struct {
+		sku       string
+		wantPrice *catalogpb.DiscountedPrice
+		wantCode  codes.Code
+	}{
+		"With the highest discount": {
+			sku:       "000003",
+			wantPrice: &catalogpb.DiscountedPrice{Original: 71000, Final: 49700, DiscountPercentage: percentage(30), Currency: "EUR"},
+		},
+		"Without discount": {
+			sku:       "000004",
+			wantPrice: &catalogpb.DiscountedPrice{Original: 79500, Final: 79500, Currency: "EUR"},
+		},
+		"Unknown sku": {
+			sku:      "999999",
+			wantCode: codes.NotFound,
+		},
+		"Empty sku": {
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := client.GetProduct(context.Background(), &catalogpb.GetProductRequest{Sku: tt.sku})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				return
+			}
+			assert.Equal(t, tt.sku, resp.Product.Sku)
+			assertPrice(t, tt.wantPrice, resp.Product.Price)
+		})
+	}
+}
+
+func TestCatalogServer_CalculatePrice(t *testing.T) {
+	client := newClient(t)
+
+	resp, err := client.CalculatePrice(context.Background(), &catalogpb.CalculatePriceRequest{Sku: "000009", Category: "boots", Price: 10000})
+
+	assert.NoError(t, err)
+	assertPrice(t, &catalogpb.DiscountedPrice{Original: 10000, Final: 7000, DiscountPercentage: percentage(30), Currency: "EUR"}, resp.Price)
+
+	_, err = client.CalculatePrice(context.Background(), &catalogpb.CalculatePriceRequest{Sku: "000009", Price: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func assertPrice(t *testing.T, want, got *catalogpb.DiscountedPrice) {
+	t.Helper()
+	assert.Equal(t, want.Original, got.Original)
+	assert.Equal(t, want.Final, got.Final)
+	assert.Equal(t, want.DiscountPercentage, got.DiscountPercentage)
+	assert.Equal(t, want.Currency, got.Currency)
+}
@@ -0,0 +1,82 @@
+package simulation
+
+import (
+	"context"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+// overlay reads the discounts of a repository with the proposed ones on top:
+// a proposed discount replaces the one of its target and audience, a 0% one
+// removing it. Nothing is written to the repository.
+type overlay struct {
+	base     catalog.DiscountRepository
+	proposed []catalog.Discount
+}
+
+// NewOverlay returns the discounts of base as they would be with proposed.
+func NewOverlay(base catalog.DiscountRepository, proposed []catalog.Discount) catalog.DiscountRepository {
+	return overlay{base, proposed}
+}
+
+func (o overlay) Find(ctx context.Context, search catalog.SearchCriteria) ([]catalog.Discount, error) {
+	found, err := o.base.Find(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	var discounts []catalog.Discount
+	for _, d := range found {
+		if !o.replaced(d) {
+			discounts = append(discounts, d)
+		}
+	}
+	for _, d := range o.proposed {
+		if d.Percentage() > 0 && matches(d, search.Filters()) {
+			discounts = append(discounts, d)
+		}
+	}
+	return discounts, nil
+}
+
+func (o overlay) replaced(d catalog.Discount) bool {
+	for _, p := range o.proposed {
+		if target(p) == target(d) && p.Audience() == d.Audience() {
+			return true
+		}
+	}
+	return false
+}
+
+type discountTarget struct {
+	kind  string
+	value string
+}
+
+func target(d catalog.Discount) discountTarget {
+	switch discount := d.(type) {
+	case *catalog.ProductDiscount:
+		return discountTarget{catalog.ProductDiscountType, string(discount.SKU())}
+	case *catalog.CategoryDiscount:
+		return discountTarget{catalog.CategoryDiscountType, string(discount.Category())}
+	}
+	return discountTarget{}
+}
+
+// matches reports whether d is found by any of the filters, as the
+// repositories find discounts.
+func matches(d catalog.Discount, filters []catalog.Filter) bool {
+	t := target(d)
+	for _, f := range filters {
+		switch filter := f.(type) {
+		case catalog.CategoryFilter:
+			if t.kind == catalog.CategoryDiscountType && t.value == string(filter.Value()) {
+				return true
+			}
+		case catalog.SKUFilter:
+			if t.kind == catalog.ProductDiscountType && t.value == string(filter.Value()) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+const pageSize = 100
+
+// Proposal is a set of discounts to simulate, 0% discounts standing for the
+// removal of the discount of their target. At is when they would start, the
+// date the lowest price of the previous 30 days is taken at.
+type Proposal struct {
+	Discounts []catalog.Discount
+	At        time.Time
+}
+
+type ProductSimulation struct {
+	SKU      catalog.SKU             `json:"sku"`
+	Name     string                  `json:"name"`
+	Category catalog.Category        `json:"category"`
+	Before   catalog.DiscountedPrice `json:"before"`
+	After    catalog.DiscountedPrice `json:"after"`
+}
+
+// Aggregates summarize a simulation over every product matching its
+// criteria. Reductions are averaged over the affected products, whose final
+// price changes, and are negative when prices go up.
+type Aggregates struct {
+	Products                   int           `json:"products"`
+	Affected                   int           `json:"affected"`
+	AverageReduction           catalog.Price `json:"average_reduction"`
+	AverageReductionPercentage float64       `json:"average_reduction_percentage"`
+}
+
+type Simulation struct {
+	Meta       catalog.PaginationMeta `json:"meta"`
+	Items      []ProductSimulation    `json:"items"`
+	Aggregates Aggregates             `json:"aggregates"`
+}
+
+// CalculaterFactory builds the calculater pricing with the given discounts.
+type CalculaterFactory func(r catalog.DiscountRepository) catalog.Calculater
+
+// LowestPricer returns the lowest price of a product over the 30 days before
+// a date.
+type LowestPricer interface {
+	LowestPriceAt(ctx context.Context, p catalog.Product, at time.Time) (catalog.Price, error)
+}
+
+// Simulator prices the catalog with proposed discounts on top of the current
+// ones, without persisting them.
+type Simulator struct {
+	products      catalog.ProductRepository
+	discounts     catalog.DiscountRepository
+	newCalculater CalculaterFactory
+	lowest        LowestPricer
+	now           func() time.Time
+}
+
+type Option func(s *Simulator)
+
+// WithLowestPrices sets the lowest price of the 30 days before the proposal
+// date on the discounted prices.
+func WithLowestPrices(lp LowestPricer) Option {
+	return func(s *Simulator) {
+		s.lowest = lp
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(s *Simulator) {
+		s.now = now
+	}
+}
+
+func NewSimulator(pr catalog.ProductRepository, dr catalog.DiscountRepository, f CalculaterFactory, opts ...Option) *Simulator {
+	s := &Simulator{products: pr, discounts: dr, newCalculater: f, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Simulate prices the products matching the search filters before and after
+// the proposal. The items are the page of the search pagination, the
+// aggregates cover every matching product.
+func (s *Simulator) Simulate(ctx context.Context, proposal Proposal, search catalog.SearchCriteria) (*Simulation, error) {
+	at := proposal.At
+	if at.IsZero() {
+		at = s.now()
+	}
+	before := s.newCalculater(s.discounts)
+	after := s.newCalculater(NewOverlay(s.discounts, proposal.Discounts))
+
+	products, err := s.all(ctx, search.Filters())
+	if err != nil {
+		return nil, err
+	}
+	sim := &Simulation{}
+	var items []ProductSimulation
+	var reduction catalog.Price
+	var reductionPercentage float64
+	for _, p := range products {
+		ps := ProductSimulation{SKU: p.SKU, Name: p.Name, Category: p.Category}
+		if ps.Before, err = s.price(ctx, before, *p, at); err != nil {
+			return nil, err
+		}
+		if ps.After, err = s.price(ctx, after, *p, at); err != nil {
+			return nil, err
+		}
+		items = append(items, ps)
+		if ps.Before.Final != ps.After.Final {
+			sim.Aggregates.Affected++
+			reduction += ps.Before.Final - ps.After.Final
+			if ps.Before.Final > 0 {
+				reductionPercentage += float64(ps.Before.Final-ps.After.Final) * 100 / float64(ps.Before.Final)
+			}
+		}
+	}
+
+	sim.Aggregates.Products = len(items)
+	if affected := sim.Aggregates.Affected; affected > 0 {
+		sim.Aggregates.AverageReduction = reduction / catalog.Price(affected)
+		sim.Aggregates.AverageReductionPercentage = math.Round(reductionPercentage/float64(affected)*100) / 100
+	}
+
+	pag := search.Pagination()
+	sim.Meta = catalog.PaginationMeta{Total: len(items), Pagination: *pag}
+	if pag.Offset < len(items) {
+		end := pag.Offset + pag.Limit
+		if end > len(items) {
+			end = len(items)
+		}
+		sim.Items = items[pag.Offset:end]
+	}
+	return sim, nil
+}
+
+func (s *Simulator) price(ctx context.Context, c catalog.Calculater, p catalog.Product, at time.Time) (catalog.DiscountedPrice, error) {
+	price, err := c.Calculate(ctx, p)
+	if err != nil {
+		return catalog.DiscountedPrice{}, err
+	}
+	if s.lowest != nil && price.DiscountPercentage != nil {
+		lowest, err := s.lowest.LowestPriceAt(ctx, p, at)
+		if err != nil {
+			return catalog.DiscountedPrice{}, err
+		}
+		price.Lowest30DaysPrice = &lowest
+	}
+	return *price, nil
+}
+
+func (s *Simulator) all(ctx context.Context, filters []catalog.Filter) ([]*catalog.Product, error) {
+	var all []*catalog.Product
+	for offset := 0; ; offset += pageSize {
+		pag, err := catalog.NewPagination(pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		page, err := s.products.List(ctx, catalog.NewSearchCriteria(pag, filters))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Items()...)
+		if len(page.Items()) < pageSize {
+			return all, nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package simulation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/history"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/simulation"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func percentage(p catalog.DiscountPercentage) *catalog.DiscountPercentage {
+	return &p
+}
+
+func newSimulator(discountRepo *inmem.DiscountRepo, opts ...simulation.Option) *simulation.Simulator {
+	productRepo := inmem.NewProductRepo([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+		catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000),
+		catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+		catalog.NewProduct("000005", "Nathane leather sneakers", "sneakers", 59000),
+	})
+	factory := func(r catalog.DiscountRepository) catalog.Calculater {
+		return pricingacl.NewCalculater(r)
+	}
+	return simulation.NewSimulator(productRepo, discountRepo, factory, opts...)
+}
+
+func search(limit, offset int, filters ...catalog.Filter) catalog.SearchCriteria {
+	pag, _ := catalog.NewPagination(limit, offset)
+	return catalog.NewSearchCriteria(pag, filters)
+}
+
+func TestSimulator_Simulate(t *testing.T) {
+	ctx := context.Background()
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{
+		catalog.NewCategoryDiscount("boots", 30),
+		catalog.NewProductDiscount("000005", 10),
+	})
+	simulator := newSimulator(discountRepo)
+
+	sim, err := simulator.Simulate(ctx, simulation.Proposal{Discounts: []catalog.Discount{
+		catalog.NewCategoryDiscount("boots", 20),
+		catalog.NewCategoryDiscount("sandals", 50),
+		catalog.NewProductDiscount("000005", 0),
+	}}, search(2, 1))
+	require.NoError(t, err)
+
+	assert.Equal(t, catalog.PaginationMeta{Total: 4, Pagination: catalog.Pagination{Limit: 2, Offset: 1}}, sim.Meta)
+	assert.Equal(t, []simulation.ProductSimulation{
+		{
+			SKU: "000003", Name: "Ashlington leather ankle boots", Category: "boots",
+			Before: *catalog.NewDiscountedPrice(71000, percentage(30)),
+			After:  *catalog.NewDiscountedPrice(71000, percentage(20)),
+		},
+		{
+			SKU: "000004", Name: "Naima embellished suede sandals", Category: "sandals",
+			Before: *catalog.NewDiscountedPrice(79500, nil),
+			After:  *catalog.NewDiscountedPrice(79500, percentage(50)),
+		},
+	}, sim.Items)
+	// boots go up 8900 and 7100 (-14.29%), sandals down 39750 (50%) and sneakers up 5900 (-11.11%)
+	assert.Equal(t, simulation.Aggregates{Products: 4, Affected: 4, AverageReduction: 4462, AverageReductionPercentage: 2.58}, sim.Aggregates)
+
+	discounts, _ := discountRepo.Find(ctx, catalog.NewSearchCriteria(nil, []catalog.Filter{catalog.NewCategoryFilter("boots"), catalog.NewSKUFilter("000005")}))
+	assert.Len(t, discounts, 2, "nothing is persisted")
+	for _, d := range discounts {
+		assert.NotEqual(t, catalog.DiscountPercentage(20), d.Percentage())
+	}
+
+	t.Run("Filtered", func(t *testing.T) {
+		sim, err := simulator.Simulate(ctx, simulation.Proposal{Discounts: []catalog.Discount{catalog.NewCategoryDiscount("sandals", 50)}}, search(5, 0, catalog.NewCategoryFilter("boots")))
+		require.NoError(t, err)
+
+		assert.Len(t, sim.Items, 2)
+		assert.Equal(t, simulation.Aggregates{Products: 2}, sim.Aggregates)
+	})
+
+	t.Run("For a pricing context", func(t *testing.T) {
+		b2b := catalog.PricingContext{Segment: "b2b"}
+		proposal := simulation.Proposal{Discounts: []catalog.Discount{catalog.NewCategoryDiscount("boots", 50, catalog.ForAudience(b2b))}}
+
+		sim, err := simulator.Simulate(catalog.WithPricingContext(ctx, b2b), proposal, search(5, 0, catalog.NewCategoryFilter("boots")))
+		require.NoError(t, err)
+		assert.Equal(t, *catalog.NewDiscountedPrice(89000, percentage(50)), sim.Items[0].After)
+
+		sim, err = simulator.Simulate(ctx, proposal, search(5, 0, catalog.NewCategoryFilter("boots")))
+		require.NoError(t, err)
+		assert.Equal(t, simulation.Aggregates{Products: 2}, sim.Aggregates, "the discount for everyone is kept")
+	})
+}
+
+func TestSimulator_lowestPrices(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2022, 3, d, 10, 0, 0, 0, time.UTC) }
+	now := day(1)
+	service := history.NewService(inmem.NewHistoryRepo(), history.WithClock(func() time.Time { return now }))
+	productRepo := inmem.NewProductRepo(nil)
+	_ = service.ProductStore(productRepo).Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 79000)})
+	now = day(10)
+	_ = service.ProductStore(productRepo).Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)})
+
+	simulator := simulation.NewSimulator(productRepo, inmem.NewDiscountRepo(nil), func(r catalog.DiscountRepository) catalog.Calculater {
+		return pricingacl.NewCalculater(r)
+	}, simulation.WithLowestPrices(service), simulation.WithClock(func() time.Time { return day(20) }))
+	proposal := simulation.Proposal{Discounts: []catalog.Discount{catalog.NewCategoryDiscount("boots", 30)}}
+
+	tests := map[string]struct {
+		at   time.Time
+		want catalog.Price
+	}{
+		"Now":                         {want: 79000},
+		"After the lower price ended": {at: day(10).AddDate(0, 0, 31), want: 89000},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			proposal.At = tt.at
+			sim, err := simulator.Simulate(ctx, proposal, search(1, 0))
+			require.NoError(t, err)
+
+			require.Len(t, sim.Items, 1)
+			assert.Nil(t, sim.Items[0].Before.Lowest30DaysPrice)
+			assert.Equal(t, &tt.want, sim.Items[0].After.Lowest30DaysPrice)
+		})
+	}
+}
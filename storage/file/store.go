@@ -0,0 +1,599 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/history"
+	"github.com/amelendres/go-catalog/storage/inmem"
+)
+
+const (
+	productDiscountType  = "product"
+	categoryDiscountType = "category"
+)
+
+var ErrClosed = NewUnavailableError(nil, "file store is closed")
+
+// Store keeps the catalog in memory and persists a JSON snapshot to path on every write,
+// along with the price history the write records.
+// Writes are serialized so snapshots are written in the order of the changes.
+// A write fails with a conflict, instead of overwriting the snapshot, when
+// another process wrote it since this one read or wrote it, and reads reload
+// the snapshot written by another process.
+type Store struct {
+	mu         sync.Mutex
+	path       string
+	products   *inmem.ProductRepo
+	discounts  *inmem.DiscountRepo
+	history    *inmem.HistoryRepo
+	coupons    *inmem.CouponRepo
+	outbox     *inmem.Outbox
+	webhooks   *WebhookRepo
+	withOutbox bool
+	onReload   []func()
+	// snapshot is the file as last read or written, nil when there was none.
+	snapshot os.FileInfo
+	// dirty is set while the memory holds a write the snapshot lacks.
+	dirty bool
+	// acked is the ID of the last event acknowledged through Outbox.
+	acked  uint64
+	closed bool
+	// productStore and discountStore write the catalog recording its history.
+	productStore  ProductStore
+	discountStore DiscountStore
+}
+
+type Option func(s *Store)
+
+// WithOutbox stores the events of every write in the snapshot along with the
+// write itself, until they are acknowledged through Outbox. Stores opened
+// without it keep the pending events of the snapshot.
+func WithOutbox() Option {
+	return func(s *Store) {
+		s.withOutbox = true
+	}
+}
+
+// OnReload calls f after the snapshot written by another process is reloaded.
+func OnReload(f func()) Option {
+	return func(s *Store) {
+		s.onReload = append(s.onReload, f)
+	}
+}
+
+type snapshot struct {
+	Products        []productRecord          `json:"products"`
+	Discounts       []discountRecord         `json:"discounts"`
+	PriceChanges    []priceChangeRecord      `json:"price_changes,omitempty"`
+	DiscountChanges []history.DiscountChange `json:"discount_changes,omitempty"`
+	Coupons         []coupons.Coupon         `json:"coupons,omitempty"`
+	Outbox          []Event                  `json:"outbox,omitempty"`
+	LastEventID     uint64                   `json:"last_event_id,omitempty"`
+}
+
+type priceChangeRecord struct {
+	SKU   SKU       `json:"sku"`
+	Price Price     `json:"price"`
+	At    time.Time `json:"at"`
+}
+
+type productRecord struct {
+	SKU      SKU      `json:"sku"`
+	Name     string   `json:"name"`
+	Category Category `json:"category"`
+	Price    Price    `json:"price"`
+}
+
+type discountRecord struct {
+	Type       string             `json:"type"`
+	Target     string             `json:"target"`
+	Percentage DiscountPercentage `json:"percentage"`
+	Audience   *PricingContext    `json:"audience,omitempty"`
+}
+
+func audienceKey(pc *PricingContext) string {
+	if pc == nil {
+		return ""
+	}
+	return pc.Segment + "/" + pc.Channel + "/" + pc.Country
+}
+
+func newDiscountRecord(t, target string, d Discount) discountRecord {
+	rec := discountRecord{Type: t, Target: target, Percentage: d.Percentage()}
+	if audience := d.Audience(); !audience.IsZero() {
+		rec.Audience = &audience
+	}
+	return rec
+}
+
+func Open(path string, opts ...Option) (*Store, error) {
+	s := &Store{path: path, outbox: inmem.NewOutbox(), history: inmem.NewHistoryRepo(), coupons: inmem.NewCouponRepo()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	var repoOpts []inmem.Option
+	if s.withOutbox {
+		repoOpts = append(repoOpts, inmem.WithOutbox(s.outbox))
+	}
+	s.products = inmem.NewProductRepo(nil, repoOpts...)
+	s.discounts = inmem.NewDiscountRepo(nil, repoOpts...)
+	// the history is recorded in memory along with the write, so both are
+	// in the same snapshot.
+	recorder := history.NewService(s.history)
+	s.productStore, s.discountStore = recorder.ProductStore(s.products), recorder.DiscountStore(s.discounts)
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	webhooks, err := openWebhookRepo(path + ".webhooks")
+	if err != nil {
+		return nil, err
+	}
+	s.webhooks = webhooks
+	return s, nil
+}
+
+// load replaces the memory with the snapshot, but for the events already
+// acknowledged.
+func (s *Store) load() error {
+	snap, info, err := readSnapshot(s.path)
+	if err != nil {
+		return err
+	}
+	acked, err := readAcked(s.ackedPath())
+	if err != nil {
+		return err
+	}
+
+	var products []*Product
+	for _, p := range snap.Products {
+		products = append(products, NewProduct(p.SKU, p.Name, p.Category, p.Price))
+	}
+	var discounts []Discount
+	for _, d := range snap.Discounts {
+		var audience []DiscountOption
+		if d.Audience != nil {
+			audience = append(audience, ForAudience(*d.Audience))
+		}
+		switch d.Type {
+		case productDiscountType:
+			discounts = append(discounts, NewProductDiscount(SKU(d.Target), d.Percentage, audience...))
+		case categoryDiscountType:
+			discounts = append(discounts, NewCategoryDiscount(Category(d.Target), d.Percentage, audience...))
+		default:
+			return fmt.Errorf("could not read snapshot %s: unknown discount type %q", s.path, d.Type)
+		}
+	}
+	var prices []history.PriceChange
+	for _, c := range snap.PriceChanges {
+		prices = append(prices, history.PriceChange{SKU: c.SKU, Price: c.Price, At: c.At})
+	}
+
+	s.products.Restore(products)
+	s.discounts.Restore(discounts)
+	s.history.Restore(prices, snap.DiscountChanges)
+	s.coupons.Restore(snap.Coupons)
+	s.outbox.Restore(snap.Outbox, snap.LastEventID)
+	_ = s.outbox.Ack(context.Background(), acked)
+	s.snapshot, s.acked = info, acked
+	return nil
+}
+
+// refresh reloads the snapshot when another process wrote it, unless the
+// memory holds a write of this one the snapshot lacks.
+func (s *Store) refresh() error {
+	s.mu.Lock()
+	if s.closed || s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	if err := s.checkUnchanged(); !errors.Is(err, ErrConflict) {
+		s.mu.Unlock()
+		return err
+	}
+	err := s.load()
+	s.mu.Unlock()
+	if err != nil {
+		return NewUnavailableError(err, "could not reload snapshot %s", s.path)
+	}
+	for _, f := range s.onReload {
+		f()
+	}
+	return nil
+}
+
+func readSnapshot(path string) (snapshot, os.FileInfo, error) {
+	var snap snapshot
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return snap, nil, nil
+	}
+	if err != nil {
+		return snap, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return snap, nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return snap, nil, err
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, nil, fmt.Errorf("could not read snapshot %s: %w", path, err)
+	}
+	return snap, info, nil
+}
+
+// ackedPath is the file keeping the ID of the last acknowledged event apart
+// from the snapshot, so acknowledging does not rewrite the catalog.
+func (s *Store) ackedPath() string {
+	return s.path + ".acked"
+}
+
+type ackedRecord struct {
+	ID uint64 `json:"id"`
+}
+
+func readAcked(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var rec ackedRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return 0, fmt.Errorf("could not read acknowledged events %s: %w", path, err)
+	}
+	return rec.ID, nil
+}
+
+func (s *Store) Products() *ProductRepo {
+	return &ProductRepo{s}
+}
+
+func (s *Store) Discounts() *DiscountRepo {
+	return &DiscountRepo{s}
+}
+
+func (s *Store) History() *HistoryRepo {
+	return &HistoryRepo{s}
+}
+
+func (s *Store) Coupons() *CouponRepo {
+	return &CouponRepo{s}
+}
+
+// Webhooks returns the webhook records, kept in the DSN.webhooks file.
+func (s *Store) Webhooks() *WebhookRepo {
+	return s.webhooks
+}
+
+// Outbox returns the pending events, nil unless the store was opened WithOutbox.
+func (s *Store) Outbox() *Outbox {
+	if !s.withOutbox {
+		return nil
+	}
+	return &Outbox{s}
+}
+
+// Ping checks the store is open and its directory is still reachable.
+func (s *Store) Ping(ctx context.Context) error {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	_, err := os.Stat(filepath.Dir(s.path))
+	return err
+}
+
+// Close writes the snapshot when the last write could not, and rejects any
+// further write.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if !s.dirty {
+		return nil
+	}
+	if err := s.checkUnchanged(); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// update applies a write and persists it. The write is not applied when the
+// store is closed or the snapshot was written by another process.
+func (s *Store) update(apply func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	if err := s.checkUnchanged(); err != nil {
+		return err
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	s.dirty = true
+	return s.flush()
+}
+
+func (s *Store) checkUnchanged() error {
+	info, err := os.Stat(s.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if s.snapshot == nil {
+			return nil
+		}
+	case err != nil:
+		return NewUnavailableError(err, "could not check snapshot %s", s.path)
+	case s.snapshot != nil && sameFile(s.snapshot, info):
+		return nil
+	}
+	return NewConflictError("snapshot %s was written by another process, reopen the store to write it", s.path)
+}
+
+// sameFile tells whether a and b describe the same version of a file, as
+// every snapshot is written to a new file renamed over the previous one.
+func sameFile(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+func (s *Store) flush() error {
+	info, err := s.write()
+	if err != nil {
+		return NewUnavailableError(err, "could not write snapshot %s", s.path)
+	}
+	s.snapshot, s.dirty = info, false
+	return nil
+}
+
+func (s *Store) write() (os.FileInfo, error) {
+	// drop the events another process acknowledged since they were read.
+	acked, err := readAcked(s.ackedPath())
+	if err != nil {
+		return nil, err
+	}
+	_ = s.outbox.Ack(context.Background(), acked)
+
+	var snap snapshot
+	for _, p := range s.products.All() {
+		snap.Products = append(snap.Products, productRecord{p.SKU, p.Name, p.Category, p.Price})
+	}
+	for _, d := range s.discounts.All() {
+		switch discount := d.(type) {
+		case *ProductDiscount:
+			snap.Discounts = append(snap.Discounts, newDiscountRecord(productDiscountType, string(discount.SKU()), d))
+		case *CategoryDiscount:
+			snap.Discounts = append(snap.Discounts, newDiscountRecord(categoryDiscountType, string(discount.Category()), d))
+		}
+	}
+	sort.Slice(snap.Discounts, func(i, j int) bool {
+		a, b := snap.Discounts[i], snap.Discounts[j]
+		if a.Type != b.Type || a.Target != b.Target {
+			return a.Type < b.Type || a.Type == b.Type && a.Target < b.Target
+		}
+		return audienceKey(a.Audience) < audienceKey(b.Audience)
+	})
+	prices, discountChanges := s.history.Snapshot()
+	for _, c := range prices {
+		snap.PriceChanges = append(snap.PriceChanges, priceChangeRecord{c.SKU, c.Price, c.At})
+	}
+	snap.DiscountChanges = discountChanges
+	snap.Coupons = s.coupons.All()
+	snap.Outbox, snap.LastEventID = s.outbox.Snapshot()
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return writeFile(s.path, data)
+}
+
+// ack persists id as the last acknowledged event, leaving the snapshot as is.
+func (s *Store) ack(ctx context.Context, id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	if id <= s.acked {
+		return nil
+	}
+	data, err := json.Marshal(ackedRecord{id})
+	if err != nil {
+		return err
+	}
+	if _, err := writeFile(s.ackedPath(), data); err != nil {
+		return NewUnavailableError(err, "could not write acknowledged events %s", s.ackedPath())
+	}
+	s.acked = id
+	return s.outbox.Ack(ctx, id)
+}
+
+// writeFile replaces path with data through a rename, so readers never see
+// a partial write.
+func writeFile(path string, data []byte) (os.FileInfo, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	return info, os.Rename(tmp.Name(), path)
+}
+
+type ProductRepo struct {
+	store *Store
+}
+
+func (r *ProductRepo) List(ctx context.Context, search SearchCriteria) (products *PaginatedProducts, err error) {
+	if err := r.store.refresh(); err != nil {
+		return nil, err
+	}
+	return r.store.products.List(ctx, search)
+}
+
+func (r *ProductRepo) Ping(ctx context.Context) error {
+	return r.store.Ping(ctx)
+}
+
+func (r *ProductRepo) Version() uint64 {
+	_ = r.store.refresh()
+	return r.store.products.Version()
+}
+
+func (r *ProductRepo) Save(products []*Product) error {
+	return r.store.update(func() error {
+		return r.store.productStore.Save(products)
+	})
+}
+
+func (r *ProductRepo) ReplaceAll(products []*Product) error {
+	return r.store.update(func() error {
+		return r.store.productStore.ReplaceAll(products)
+	})
+}
+
+type DiscountRepo struct {
+	store *Store
+}
+
+func (r *DiscountRepo) Find(ctx context.Context, search SearchCriteria) (discounts []Discount, err error) {
+	return r.store.discounts.Find(ctx, search)
+}
+
+func (r *DiscountRepo) Ping(ctx context.Context) error {
+	return r.store.Ping(ctx)
+}
+
+func (r *DiscountRepo) Version() uint64 {
+	_ = r.store.refresh()
+	return r.store.discounts.Version()
+}
+
+func (r *DiscountRepo) Save(discounts []Discount) error {
+	return r.store.update(func() error {
+		return r.store.discountStore.Save(discounts)
+	})
+}
+
+func (r *DiscountRepo) ReplaceAll(discounts []Discount) error {
+	return r.store.update(func() error {
+		return r.store.discountStore.ReplaceAll(discounts)
+	})
+}
+
+// HistoryRepo persists the price and discount changes along with the catalog.
+type HistoryRepo struct {
+	store *Store
+}
+
+func (r *HistoryRepo) AddPriceChanges(ctx context.Context, changes []history.PriceChange) error {
+	return r.store.update(func() error {
+		return r.store.history.AddPriceChanges(ctx, changes)
+	})
+}
+
+func (r *HistoryRepo) AddDiscountChanges(ctx context.Context, changes []history.DiscountChange) error {
+	return r.store.update(func() error {
+		return r.store.history.AddDiscountChanges(ctx, changes)
+	})
+}
+
+func (r *HistoryRepo) PriceChanges(ctx context.Context, sku SKU) ([]history.PriceChange, error) {
+	return r.store.history.PriceChanges(ctx, sku)
+}
+
+func (r *HistoryRepo) DiscountChanges(ctx context.Context, sku SKU, category Category) ([]history.DiscountChange, error) {
+	return r.store.history.DiscountChanges(ctx, sku, category)
+}
+
+func (r *HistoryRepo) LatestDiscounts(ctx context.Context) ([]history.DiscountChange, error) {
+	return r.store.history.LatestDiscounts(ctx)
+}
+
+func (r *HistoryRepo) SupersededPrices(ctx context.Context, t time.Time) (int, error) {
+	return r.store.history.SupersededPrices(ctx, t)
+}
+
+type CouponRepo struct {
+	store *Store
+}
+
+func (r *CouponRepo) Save(ctx context.Context, cs []coupons.Coupon) error {
+	return r.store.update(func() error {
+		return r.store.coupons.Save(ctx, cs)
+	})
+}
+
+// Find reads the coupons imported by another process too.
+func (r *CouponRepo) Find(ctx context.Context, code string) (*coupons.Coupon, error) {
+	if err := r.store.refresh(); err != nil {
+		return nil, err
+	}
+	return r.store.coupons.Find(ctx, code)
+}
+
+func (r *CouponRepo) Redeem(ctx context.Context, code string) (c *coupons.Coupon, err error) {
+	err = r.store.update(func() error {
+		c, err = r.store.coupons.Redeem(ctx, code)
+		return err
+	})
+	return c, err
+}
+
+type Outbox struct {
+	store *Store
+}
+
+// Pending returns the events not acknowledged yet, the ones written by
+// another process included.
+func (o *Outbox) Pending(ctx context.Context, limit int) ([]Event, error) {
+	if err := o.store.refresh(); err != nil {
+		return nil, err
+	}
+	return o.store.outbox.Pending(ctx, limit)
+}
+
+// Ack drops the dispatched events up to id. Only the ID is persisted, the
+// snapshot written by the next catalog write leaving the events out.
+func (o *Outbox) Ack(ctx context.Context, id uint64) error {
+	return o.store.ack(ctx, id)
+}
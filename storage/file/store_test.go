@@ -0,0 +1,294 @@
+package file_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/coupons"
+	"github.com/amelendres/go-catalog/history"
+	"github.com/amelendres/go-catalog/storage/file"
+	"github.com/amelendres/go-catalog/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+
+	store, err := file.Open(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Products().Save([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+	}))
+	assert.NoError(t, store.Discounts().Save([]catalog.Discount{
+		catalog.NewCategoryDiscount("boots", 30),
+		catalog.NewCategoryDiscount("boots", 40, catalog.ForAudience(catalog.PricingContext{Segment: "b2b", Country: "ES"})),
+		catalog.NewProductDiscount("000001", 15),
+	}))
+
+	reopened, err := file.Open(path)
+	assert.NoError(t, err)
+
+	pag, _ := catalog.NewPagination(5, 0)
+	products, err := reopened.Products().List(context.Background(), catalog.NewSearchCriteria(pag, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)}, products.Items())
+
+	discounts, err := reopened.Discounts().Find(context.Background(), catalog.NewSearchCriteria(nil, []catalog.Filter{
+		catalog.NewCategoryFilter("boots"),
+		catalog.NewSKUFilter("000001"),
+	}))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []catalog.Discount{
+		catalog.NewCategoryDiscount("boots", 30),
+		catalog.NewCategoryDiscount("boots", 40, catalog.ForAudience(catalog.PricingContext{Segment: "b2b", Country: "ES"})),
+		catalog.NewProductDiscount("000001", 15),
+	}, discounts)
+}
+
+func TestStore_WrittenByAnotherProcess(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	boots := catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)
+	hat := catalog.NewProduct("000006", "AA hat", "hats", 72000)
+	seeded, err := file.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, seeded.Products().Save([]*catalog.Product{boots}))
+
+	server, err := file.Open(path)
+	require.NoError(t, err)
+	importer, err := file.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, importer.Products().Save([]*catalog.Product{hat}))
+	require.NoError(t, importer.Close())
+
+	trainers := catalog.NewProduct("000002", "BV Lean leather trainers", "boots", 99000)
+	err = server.Products().Save([]*catalog.Product{trainers})
+	assert.ErrorIs(t, err, catalog.ErrConflict)
+	assert.NoError(t, server.Close(), "a store without pending writes closes without writing")
+
+	reopened, err := file.Open(path)
+	require.NoError(t, err)
+	pag, _ := catalog.NewPagination(5, 0)
+	products, _ := reopened.Products().List(ctx, catalog.NewSearchCriteria(pag, nil))
+	assert.Equal(t, []*catalog.Product{boots, hat}, products.Items(), "a conflicting write is not applied")
+}
+
+func TestStore_Reload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	boots := catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)
+	hat := catalog.NewProduct("000006", "AA hat", "hats", 72000)
+	reloads := 0
+	server, err := file.Open(path, file.OnReload(func() { reloads++ }))
+	require.NoError(t, err)
+	importer, err := file.Open(path)
+	require.NoError(t, err)
+
+	version := server.Products().Version()
+	require.NoError(t, importer.Products().Save([]*catalog.Product{boots}))
+	require.NoError(t, importer.Discounts().Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)}))
+
+	pag, _ := catalog.NewPagination(5, 0)
+	products, err := server.Products().List(ctx, catalog.NewSearchCriteria(pag, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []*catalog.Product{boots}, products.Items(), "reads reload the snapshot written by another process")
+	assert.NotEqual(t, version, server.Products().Version())
+	discounts, _ := server.Discounts().Find(ctx, catalog.NewSearchCriteria(nil, []catalog.Filter{catalog.NewCategoryFilter("boots")}))
+	assert.Equal(t, []catalog.Discount{catalog.NewCategoryDiscount("boots", 30)}, discounts)
+	assert.Equal(t, 1, reloads)
+
+	require.NoError(t, server.Products().Save([]*catalog.Product{hat}), "a reloaded store writes again")
+	reopened, err := file.Open(path)
+	require.NoError(t, err)
+	products, _ = reopened.Products().List(ctx, catalog.NewSearchCriteria(pag, nil))
+	assert.Equal(t, []*catalog.Product{boots, hat}, products.Items())
+}
+
+func TestStore_History(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	at := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	thirty := catalog.DiscountPercentage(30)
+
+	store, err := file.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, store.History().AddPriceChanges(ctx, []history.PriceChange{
+		{SKU: "000001", Price: 90000, At: at},
+		{SKU: "000001", Price: 80000, At: at.Add(time.Hour)},
+	}))
+	require.NoError(t, store.History().AddDiscountChanges(ctx, []history.DiscountChange{
+		{Type: history.CategoryDiscountType, Target: "boots", Percentage: &thirty, At: at},
+		{Type: history.CategoryDiscountType, Target: "boots", At: at.Add(time.Hour)},
+	}))
+
+	reopened, err := file.Open(path)
+	require.NoError(t, err)
+	prices, err := reopened.History().PriceChanges(ctx, "000001")
+	assert.NoError(t, err)
+	assert.Equal(t, []history.PriceChange{
+		{SKU: "000001", Price: 90000, At: at},
+		{SKU: "000001", Price: 80000, At: at.Add(time.Hour)},
+	}, prices)
+	discounts, err := reopened.History().DiscountChanges(ctx, "000001", "boots")
+	assert.NoError(t, err)
+	assert.Equal(t, []history.DiscountChange{
+		{Type: history.CategoryDiscountType, Target: "boots", Percentage: &thirty, At: at},
+		{Type: history.CategoryDiscountType, Target: "boots", At: at.Add(time.Hour)},
+	}, discounts)
+}
+
+func TestStore_RecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+
+	store, err := file.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Products().Save([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+	}))
+	require.NoError(t, store.Discounts().ReplaceAll([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)}))
+	require.NoError(t, store.Discounts().ReplaceAll(nil))
+
+	reopened, err := file.Open(path)
+	require.NoError(t, err)
+	prices, err := reopened.History().PriceChanges(ctx, "000001")
+	assert.NoError(t, err)
+	if assert.Len(t, prices, 1) {
+		assert.Equal(t, catalog.Price(89000), prices[0].Price)
+	}
+	discounts, err := reopened.History().DiscountChanges(ctx, "000001", "boots")
+	assert.NoError(t, err)
+	if assert.Len(t, discounts, 2, "the discount and its removal") {
+		assert.Equal(t, catalog.DiscountPercentage(30), *discounts[0].Percentage)
+		assert.Nil(t, discounts[1].Percentage)
+	}
+}
+
+func TestStore_Coupons(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+
+	server, err := file.Open(path)
+	require.NoError(t, err)
+	importer, err := file.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, importer.Coupons().Save(ctx, []coupons.Coupon{{Code: "BOOTS40", Percentage: 40, MaxUses: 2}}))
+	_, err = server.Coupons().Find(ctx, "BOOTS40")
+	require.NoError(t, err, "reads reload the coupons imported by another process")
+	_, err = server.Coupons().Redeem(ctx, "BOOTS40")
+	require.NoError(t, err)
+
+	reopened, err := file.Open(path)
+	require.NoError(t, err)
+	got, err := reopened.Coupons().Find(ctx, "BOOTS40")
+	assert.NoError(t, err)
+	assert.Equal(t, &coupons.Coupon{Code: "BOOTS40", Percentage: 40, MaxUses: 2, Uses: 1}, got)
+}
+
+func TestStore_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	sub := webhooks.Subscription{ID: "a1", URL: "https://partner.example/prices", Secret: "s3cr3t", CreatedAt: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)}
+	price := *catalog.NewDiscountedPrice(89000, nil)
+
+	store, err := file.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Webhooks().Add(ctx, sub))
+	pending := []webhooks.Delivery{{SubscriptionID: "a1", Payload: webhooks.Payload{ID: "p1", Type: webhooks.PriceChangedEvent, SKU: "000001", After: price}}}
+	require.NoError(t, store.Webhooks().SavePrices(ctx, map[catalog.SKU]catalog.DiscountedPrice{"000001": price}, pending))
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist, "webhooks are kept apart from the snapshot")
+
+	reopened, err := file.Open(path)
+	require.NoError(t, err)
+	subs, _ := reopened.Webhooks().List(ctx)
+	assert.Equal(t, []webhooks.Subscription{sub}, subs)
+	prices, _ := reopened.Webhooks().Prices(ctx)
+	assert.Equal(t, map[catalog.SKU]catalog.DiscountedPrice{"000001": price}, prices)
+	got, _ := reopened.Webhooks().Pending(ctx)
+	assert.Equal(t, pending, got)
+
+	require.NoError(t, reopened.Webhooks().Delivered(ctx, "a1", "p1"))
+	reopened, err = file.Open(path)
+	require.NoError(t, err)
+	got, _ = reopened.Webhooks().Pending(ctx)
+	assert.Empty(t, got, "delivered payloads are no longer pending")
+}
+
+func TestStore_ConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	store, err := file.Open(path)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				sku := catalog.SKU(fmt.Sprintf("w%d-%03d", w, i))
+				assert.NoError(t, store.Products().Save([]*catalog.Product{catalog.NewProduct(sku, "Boots", "boots", 1000)}))
+				assert.NoError(t, store.Discounts().Save([]catalog.Discount{catalog.NewProductDiscount(sku, 10)}))
+			}
+		}(w)
+	}
+	wg.Wait()
+	assert.NoError(t, store.Close())
+
+	reopened, err := file.Open(path)
+	assert.NoError(t, err)
+	pag, _ := catalog.NewPagination(100, 0)
+	products, err := reopened.Products().List(context.Background(), catalog.NewSearchCriteria(pag, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 40, products.Meta.Total)
+}
+
+func TestStore_Outbox(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+
+	store, err := file.Open(path, file.WithOutbox())
+	assert.NoError(t, err)
+	assert.NoError(t, store.Products().Save([]*catalog.Product{catalog.NewProduct("000001", "Boots", "boots", 1000)}))
+	assert.NoError(t, store.Discounts().Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)}))
+	written, _ := os.Stat(path)
+	assert.NoError(t, store.Outbox().Ack(ctx, 1))
+	acked, _ := os.Stat(path)
+	assert.True(t, os.SameFile(written, acked), "acknowledging does not rewrite the snapshot")
+
+	reopened, err := file.Open(path, file.WithOutbox())
+	assert.NoError(t, err)
+	pending, err := reopened.Outbox().Pending(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, catalog.DiscountAppliedEvent, pending[0].Type)
+	assert.Equal(t, uint64(2), pending[0].ID)
+
+	assert.NoError(t, reopened.Products().Save([]*catalog.Product{catalog.NewProduct("000002", "Hat", "hats", 500)}))
+	pending, _ = reopened.Outbox().Pending(ctx, 10)
+	assert.Equal(t, uint64(3), pending[1].ID, "event IDs continue after reopening")
+
+	withoutOutbox, err := file.Open(path)
+	assert.NoError(t, err)
+	assert.Nil(t, withoutOutbox.Outbox())
+	assert.NoError(t, withoutOutbox.Products().Save([]*catalog.Product{catalog.NewProduct("000003", "Cap", "hats", 300)}))
+
+	pending, err = store.Outbox().Pending(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{2, 3}, eventIDs(pending), "the events written by other processes are pending, and kept by stores without outbox")
+}
+
+func eventIDs(events []catalog.Event) []uint64 {
+	var ids []uint64
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
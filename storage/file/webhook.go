@@ -0,0 +1,114 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	. "github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/webhooks"
+)
+
+// WebhookRepo persists the webhook subscriptions, their dead letters, the
+// prices they were notified of and the deliveries still pending to a file of
+// their own next to the snapshot, as only the server writes them.
+type WebhookRepo struct {
+	mu   sync.Mutex
+	path string
+	repo *inmem.WebhookRepo
+}
+
+type webhooksRecord struct {
+	Subscriptions []webhooks.Subscription `json:"subscriptions"`
+	DeadLetters   []webhooks.DeadLetter   `json:"dead_letters"`
+	Prices        map[SKU]DiscountedPrice `json:"prices"`
+	Pending       []webhooks.Delivery     `json:"pending,omitempty"`
+}
+
+func openWebhookRepo(path string) (*WebhookRepo, error) {
+	var rec webhooksRecord
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("could not read webhooks %s: %w", path, err)
+		}
+	}
+	repo := inmem.NewWebhookRepo()
+	repo.Restore(rec.Subscriptions, rec.DeadLetters, rec.Prices, rec.Pending)
+	return &WebhookRepo{path: path, repo: repo}, nil
+}
+
+func (r *WebhookRepo) Add(ctx context.Context, s webhooks.Subscription) error {
+	return r.update(func() error {
+		return r.repo.Add(ctx, s)
+	})
+}
+
+func (r *WebhookRepo) Remove(ctx context.Context, id string) error {
+	return r.update(func() error {
+		return r.repo.Remove(ctx, id)
+	})
+}
+
+func (r *WebhookRepo) List(ctx context.Context) ([]webhooks.Subscription, error) {
+	return r.repo.List(ctx)
+}
+
+func (r *WebhookRepo) AddDeadLetter(ctx context.Context, d webhooks.DeadLetter) error {
+	return r.update(func() error {
+		return r.repo.AddDeadLetter(ctx, d)
+	})
+}
+
+func (r *WebhookRepo) DeadLetters(ctx context.Context) ([]webhooks.DeadLetter, error) {
+	return r.repo.DeadLetters(ctx)
+}
+
+func (r *WebhookRepo) Prices(ctx context.Context) (map[SKU]DiscountedPrice, error) {
+	return r.repo.Prices(ctx)
+}
+
+func (r *WebhookRepo) SavePrices(ctx context.Context, prices map[SKU]DiscountedPrice, pending []webhooks.Delivery) error {
+	return r.update(func() error {
+		return r.repo.SavePrices(ctx, prices, pending)
+	})
+}
+
+func (r *WebhookRepo) Pending(ctx context.Context) ([]webhooks.Delivery, error) {
+	return r.repo.Pending(ctx)
+}
+
+func (r *WebhookRepo) Delivered(ctx context.Context, subscriptionID, payloadID string) error {
+	return r.update(func() error {
+		return r.repo.Delivered(ctx, subscriptionID, payloadID)
+	})
+}
+
+// update applies a write and persists every webhook record, in the order of
+// the writes.
+func (r *WebhookRepo) update(apply func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := apply(); err != nil {
+		return err
+	}
+	var rec webhooksRecord
+	rec.Subscriptions, rec.DeadLetters, rec.Prices, rec.Pending = r.repo.Snapshot()
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := writeFile(r.path, data); err != nil {
+		return NewUnavailableError(err, "could not write webhooks %s", r.path)
+	}
+	return nil
+}
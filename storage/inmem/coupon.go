@@ -0,0 +1,86 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/amelendres/go-catalog/coupons"
+)
+
+type CouponRepo struct {
+	mu      sync.RWMutex
+	coupons map[string]coupons.Coupon
+}
+
+func (r *CouponRepo) Save(ctx context.Context, cs []coupons.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range cs {
+		if previous, ok := r.coupons[c.Code]; ok {
+			c.Uses = previous.Uses
+		}
+		r.coupons[c.Code] = c
+	}
+	return nil
+}
+
+func (r *CouponRepo) Find(ctx context.Context, code string) (*coupons.Coupon, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.coupons[code]
+	if !ok {
+		return nil, coupons.ErrCouponNotFound
+	}
+	return &c, nil
+}
+
+func (r *CouponRepo) Redeem(ctx context.Context, code string) (*coupons.Coupon, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.coupons[code]
+	if !ok {
+		return nil, coupons.ErrCouponNotFound
+	}
+	if c.MaxUses > 0 && c.Uses >= c.MaxUses {
+		return nil, coupons.ErrCouponExhausted
+	}
+	c.Uses++
+	r.coupons[code] = c
+	return &c, nil
+}
+
+// Restore replaces the coupons with the ones stored by another process.
+func (r *CouponRepo) Restore(cs []coupons.Coupon) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.coupons = make(map[string]coupons.Coupon, len(cs))
+	for _, c := range cs {
+		r.coupons[c.Code] = c
+	}
+}
+
+// All returns the coupons by code.
+func (r *CouponRepo) All() []coupons.Coupon {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cs := make([]coupons.Coupon, 0, len(r.coupons))
+	for _, c := range r.coupons {
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Code < cs[j].Code })
+	return cs
+}
+
+func NewCouponRepo(cs ...coupons.Coupon) *CouponRepo {
+	r := &CouponRepo{coupons: make(map[string]coupons.Coupon)}
+	for _, c := range cs {
+		r.coupons[c.Code] = c
+	}
+	return r
+}
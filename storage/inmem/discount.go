@@ -1,43 +1,160 @@
 package inmem
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
+
 	. "github.com/amelendres/go-catalog/catalog"
 )
 
+// DiscountRepo is safe for concurrent use, guarding its indexes with a
+// read-write lock. It keeps a discount per target and audience.
 type DiscountRepo struct {
-	products   map[string]Discount
-	categories map[string]Discount
+	mu         sync.RWMutex
+	products   map[string][]Discount
+	categories map[string][]Discount
+	version    uint64
+	outbox     *Outbox
 }
 
-func (r *DiscountRepo) Find(search SearchCriteria) (discounts []Discount, err error) {
+func (r *DiscountRepo) Find(ctx context.Context, search SearchCriteria) (discounts []Discount, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	var resp []Discount
 	for _, f := range search.Filters() {
 		switch filter := f.(type) {
 		case CategoryFilter:
-			if discount, ok := r.categories[string(filter.Value())]; ok {
-				resp = append(resp, discount)
-			}
+			resp = append(resp, r.categories[string(filter.Value())]...)
 		case SKUFilter:
-			if discount, ok := r.products[string(filter.Value())]; ok {
-				resp = append(resp, discount)
-			}
+			resp = append(resp, r.products[string(filter.Value())]...)
 		}
 	}
 
 	return resp, nil
 }
 
-func NewDiscountRepo(discounts []Discount) *DiscountRepo {
-	productDiscounts := make(map[string]Discount)
-	categoryDiscounts := make(map[string]Discount)
+func (r *DiscountRepo) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *DiscountRepo) Save(discounts []Discount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
+	var events []Event
 	for _, d := range discounts {
-		switch discount := d.(type) {
-		case *CategoryDiscount:
-			categoryDiscounts[string(discount.Category())] = discount
-		case *ProductDiscount:
-			productDiscounts[string(discount.SKU())] = discount
+		if previous, ok := r.find(d); !ok || previous.Percentage() != d.Percentage() {
+			events = append(events, NewDiscountAppliedEvent(d))
+		}
+		r.add(d)
+	}
+	r.outbox.append(events)
+	atomic.AddUint64(&r.version, 1)
+	return nil
+}
+
+func (r *DiscountRepo) ReplaceAll(discounts []Discount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := &DiscountRepo{products: r.products, categories: r.categories}
+	r.products = make(map[string][]Discount)
+	r.categories = make(map[string][]Discount)
+	var events []Event
+	for _, d := range discounts {
+		if p, ok := previous.find(d); !ok || p.Percentage() != d.Percentage() {
+			events = append(events, NewDiscountAppliedEvent(d))
+		}
+		r.add(d)
+	}
+	for _, d := range previous.all() {
+		if _, ok := r.find(d); !ok {
+			events = append(events, NewDiscountExpiredEvent(d))
+		}
+	}
+	r.outbox.append(events)
+	atomic.AddUint64(&r.version, 1)
+	return nil
+}
+
+// Restore replaces the discounts with the ones stored by another process,
+// without events as that process emitted them.
+func (r *DiscountRepo) Restore(discounts []Discount) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.products = make(map[string][]Discount)
+	r.categories = make(map[string][]Discount)
+	for _, d := range discounts {
+		r.add(d)
+	}
+	atomic.AddUint64(&r.version, 1)
+}
+
+func (r *DiscountRepo) Version() uint64 {
+	return atomic.LoadUint64(&r.version)
+}
+
+func (r *DiscountRepo) All() []Discount {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.all()
+}
+
+func (r *DiscountRepo) all() []Discount {
+	var discounts []Discount
+	for _, ds := range r.categories {
+		discounts = append(discounts, ds...)
+	}
+	for _, ds := range r.products {
+		discounts = append(discounts, ds...)
+	}
+	return discounts
+}
+
+// index returns the index of the discounts of the target of d.
+func (r *DiscountRepo) index(d Discount) (map[string][]Discount, string) {
+	switch discount := d.(type) {
+	case *CategoryDiscount:
+		return r.categories, string(discount.Category())
+	case *ProductDiscount:
+		return r.products, string(discount.SKU())
+	}
+	return nil, ""
+}
+
+// find returns the discount of the target and audience of d.
+func (r *DiscountRepo) find(d Discount) (Discount, bool) {
+	index, target := r.index(d)
+	for _, found := range index[target] {
+		if found.Audience() == d.Audience() {
+			return found, true
+		}
+	}
+	return nil, false
+}
+
+func (r *DiscountRepo) add(d Discount) {
+	index, target := r.index(d)
+	if index == nil {
+		return
+	}
+	for i, found := range index[target] {
+		if found.Audience() == d.Audience() {
+			index[target][i] = d
+			return
 		}
 	}
-	return &DiscountRepo{productDiscounts, categoryDiscounts}
+	index[target] = append(index[target], d)
+}
+
+func NewDiscountRepo(discounts []Discount, opts ...Option) *DiscountRepo {
+	r := &DiscountRepo{products: make(map[string][]Discount), categories: make(map[string][]Discount), outbox: newOptions(opts).outbox}
+	for _, d := range discounts {
+		r.add(d)
+	}
+	return r
 }
@@ -0,0 +1,80 @@
+package inmem_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscountRepo_ConcurrentFindAndSave(t *testing.T) {
+	repo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	search := catalog.NewSearchCriteria(nil, []catalog.Filter{
+		catalog.NewCategoryFilter("boots"),
+		catalog.NewSKUFilter("000001"),
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				sku := catalog.SKU(fmt.Sprintf("w%d-%03d", w, i))
+				assert.NoError(t, repo.Save([]catalog.Discount{catalog.NewProductDiscount(sku, 10)}))
+				if i%10 == 0 {
+					assert.NoError(t, repo.ReplaceAll([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)}))
+				}
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				discounts, err := repo.Find(context.Background(), search)
+				assert.NoError(t, err)
+				assert.Len(t, discounts, 1)
+				_ = repo.All()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDiscountRepo_Events(t *testing.T) {
+	outbox := inmem.NewOutbox()
+	repo := inmem.NewDiscountRepo(nil, inmem.WithOutbox(outbox))
+
+	_ = repo.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30), catalog.NewProductDiscount("000001", 15)})
+	_ = repo.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	_ = repo.ReplaceAll([]catalog.Discount{catalog.NewCategoryDiscount("boots", 20)})
+
+	pending, _ := outbox.Pending(context.Background(), 10)
+	var got []string
+	for _, e := range pending {
+		got = append(got, fmt.Sprintf("%s %s %s%s", e.Type, e.DiscountType, e.Category, e.SKU))
+	}
+	assert.Equal(t, []string{
+		"discount.applied category boots",
+		"discount.applied product 000001",
+		"discount.applied category boots",
+		"discount.expired product 000001",
+	}, got)
+}
+
+func TestDiscountRepo_Audiences(t *testing.T) {
+	b2b := catalog.ForAudience(catalog.PricingContext{Segment: "b2b"})
+	repo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	search := catalog.NewSearchCriteria(nil, []catalog.Filter{catalog.NewCategoryFilter("boots")})
+
+	_ = repo.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 40, b2b)})
+	discounts, _ := repo.Find(context.Background(), search)
+	assert.Equal(t, []catalog.Discount{catalog.NewCategoryDiscount("boots", 30), catalog.NewCategoryDiscount("boots", 40, b2b)}, discounts)
+
+	_ = repo.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 45, b2b)})
+	discounts, _ = repo.Find(context.Background(), search)
+	assert.Equal(t, []catalog.Discount{catalog.NewCategoryDiscount("boots", 30), catalog.NewCategoryDiscount("boots", 45, b2b)}, discounts, "a discount replaces the one of its target and audience")
+}
@@ -0,0 +1,120 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/history"
+)
+
+type HistoryRepo struct {
+	mu        sync.RWMutex
+	prices    map[SKU][]history.PriceChange
+	discounts []history.DiscountChange
+}
+
+func (r *HistoryRepo) AddPriceChanges(ctx context.Context, changes []history.PriceChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range changes {
+		r.prices[c.SKU] = append(r.prices[c.SKU], c)
+	}
+	return nil
+}
+
+func (r *HistoryRepo) AddDiscountChanges(ctx context.Context, changes []history.DiscountChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.discounts = append(r.discounts, changes...)
+	return nil
+}
+
+func (r *HistoryRepo) PriceChanges(ctx context.Context, sku SKU) ([]history.PriceChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]history.PriceChange(nil), r.prices[sku]...), nil
+}
+
+func (r *HistoryRepo) DiscountChanges(ctx context.Context, sku SKU, category Category) ([]history.DiscountChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var changes []history.DiscountChange
+	for _, c := range r.discounts {
+		if c.Type == history.ProductDiscountType && c.Target == string(sku) ||
+			c.Type == history.CategoryDiscountType && c.Target == string(category) {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+func (r *HistoryRepo) LatestDiscounts(ctx context.Context) ([]history.DiscountChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index := make(map[[2]string]int)
+	var latest []history.DiscountChange
+	for _, c := range r.discounts {
+		key := [2]string{c.Type, c.Target}
+		if i, ok := index[key]; ok {
+			latest[i] = c
+			continue
+		}
+		index[key] = len(latest)
+		latest = append(latest, c)
+	}
+	return latest, nil
+}
+
+func (r *HistoryRepo) SupersededPrices(ctx context.Context, t time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	for _, changes := range r.prices {
+		for i := 1; i < len(changes) && !changes[i].At.After(t); i++ {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Restore loads the changes of a previous run, in the order they were added.
+func (r *HistoryRepo) Restore(prices []history.PriceChange, discounts []history.DiscountChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prices = make(map[SKU][]history.PriceChange)
+	for _, c := range prices {
+		r.prices[c.SKU] = append(r.prices[c.SKU], c)
+	}
+	r.discounts = append([]history.DiscountChange(nil), discounts...)
+}
+
+// Snapshot returns every change, the price ones by SKU.
+func (r *HistoryRepo) Snapshot() ([]history.PriceChange, []history.DiscountChange) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	skus := make([]SKU, 0, len(r.prices))
+	for sku := range r.prices {
+		skus = append(skus, sku)
+	}
+	sort.Slice(skus, func(i, j int) bool { return skus[i] < skus[j] })
+	var prices []history.PriceChange
+	for _, sku := range skus {
+		prices = append(prices, r.prices[sku]...)
+	}
+	return prices, append([]history.DiscountChange(nil), r.discounts...)
+}
+
+func NewHistoryRepo() *HistoryRepo {
+	return &HistoryRepo{prices: make(map[SKU][]history.PriceChange)}
+}
@@ -0,0 +1,98 @@
+package inmem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/amelendres/go-catalog/catalog"
+)
+
+// Outbox keeps the events emitted by the repositories sharing it until they
+// are acknowledged as dispatched.
+type Outbox struct {
+	mu     sync.Mutex
+	events []Event
+	lastID uint64
+	now    func() time.Time
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{now: time.Now}
+}
+
+// Restore loads the pending events of a previous run.
+func (o *Outbox) Restore(events []Event, lastID uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.events = append([]Event(nil), events...)
+	o.lastID = lastID
+}
+
+func (o *Outbox) Pending(ctx context.Context, limit int) ([]Event, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if limit > len(o.events) {
+		limit = len(o.events)
+	}
+	return append([]Event(nil), o.events[:limit]...), nil
+}
+
+// Ack drops the dispatched events up to id, events being dispatched in order.
+func (o *Outbox) Ack(ctx context.Context, id uint64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i := 0
+	for i < len(o.events) && o.events[i].ID <= id {
+		i++
+	}
+	o.events = append([]Event(nil), o.events[i:]...)
+	return nil
+}
+
+// Snapshot returns every pending event and the ID of the last stored one.
+func (o *Outbox) Snapshot() ([]Event, uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Event(nil), o.events...), o.lastID
+}
+
+func (o *Outbox) append(events []Event) {
+	if o == nil || len(events) == 0 {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	at := o.now()
+	for _, e := range events {
+		o.lastID++
+		e.ID = o.lastID
+		e.OccurredAt = at
+		o.events = append(o.events, e)
+	}
+}
+
+type Option func(o *options)
+
+type options struct {
+	outbox *Outbox
+}
+
+// WithOutbox stores the events of every write in o, within the write itself.
+func WithOutbox(o *Outbox) Option {
+	return func(opts *options) {
+		opts.outbox = o
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
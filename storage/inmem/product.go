@@ -1,14 +1,25 @@
 package inmem
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
+
 	. "github.com/amelendres/go-catalog/catalog"
 )
 
+// ProductRepo is safe for concurrent use: readers share a read lock and get
+// their own copy of the page, writers take the write lock.
 type ProductRepo struct {
+	mu       sync.RWMutex
 	products []*Product
+	version  uint64
+	outbox   *Outbox
 }
 
-func (r *ProductRepo) List(search SearchCriteria) (products *PaginatedProducts, err error) {
+func (r *ProductRepo) List(ctx context.Context, search SearchCriteria) (products *PaginatedProducts, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	filteredProducts := r.filter(search.Filters())
 	paginated := paginate(filteredProducts, *search.Pagination())
@@ -16,26 +27,98 @@ func (r *ProductRepo) List(search SearchCriteria) (products *PaginatedProducts,
 	return paginated, nil
 }
 
-func (r *ProductRepo) filter(filters []Filter) []*Product {
+func (r *ProductRepo) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *ProductRepo) Save(products []*Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []Event
+	for _, p := range products {
+		if i := r.indexOf(p.SKU); i >= 0 {
+			events = appendProductEvents(events, r.products[i], p)
+			r.products[i] = p
+			continue
+		}
+		events = appendProductEvents(events, nil, p)
+		r.products = append(r.products, p)
+	}
+	r.outbox.append(events)
+	atomic.AddUint64(&r.version, 1)
+	return nil
+}
+
+func (r *ProductRepo) ReplaceAll(products []*Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	var filteredProducts []*Product
-	if len(filters) == 0 {
-		filteredProducts = r.products
+	var events []Event
+	for _, p := range products {
+		var previous *Product
+		if i := r.indexOf(p.SKU); i >= 0 {
+			previous = r.products[i]
+		}
+		events = appendProductEvents(events, previous, p)
+	}
+	r.products = append([]*Product(nil), products...)
+	r.outbox.append(events)
+	atomic.AddUint64(&r.version, 1)
+	return nil
+}
+
+func appendProductEvents(events []Event, previous, p *Product) []Event {
+	switch {
+	case previous == nil:
+		return append(events, NewProductCreatedEvent(p))
+	case previous.Price != p.Price:
+		return append(events, NewPriceChangedEvent(p, previous.Price))
 	}
+	return events
+}
+
+// Restore replaces the products with the ones stored by another process,
+// without events as that process emitted them.
+func (r *ProductRepo) Restore(products []*Product) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
+	r.products = append([]*Product(nil), products...)
+	atomic.AddUint64(&r.version, 1)
+}
+
+func (r *ProductRepo) Version() uint64 {
+	return atomic.LoadUint64(&r.version)
+}
+
+func (r *ProductRepo) All() []*Product {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]*Product(nil), r.products...)
+}
+
+func (r *ProductRepo) indexOf(sku SKU) int {
+	for i, p := range r.products {
+		if p.SKU == sku {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *ProductRepo) filter(filters []Filter) []*Product {
+
+	filteredProducts := r.products
 	for _, f := range filters {
 		switch filter := f.(type) {
 		case CategoryFilter:
-			if filteredProducts != nil {
-				filteredProducts = append(filteredProducts, filterByCategory(filteredProducts, filter.Value())...)
-			}
-			filteredProducts = append(filteredProducts, filterByCategory(r.products, filter.Value())...)
+			filteredProducts = filterByCategory(filteredProducts, filter.Value())
 		case PriceLessThanFilter:
-			if filteredProducts != nil {
-				filteredProducts = append(filteredProducts, filterByPriceLessThan(filteredProducts, filter.Value())...)
-			}
-			filteredProducts = append(filteredProducts, filterByPriceLessThan(r.products, filter.Value())...)
-
+			filteredProducts = filterByPriceLessThan(filteredProducts, filter.Value())
+		case SKUFilter:
+			filteredProducts = filterBySKU(filteredProducts, filter.Value())
 		}
 	}
 	return filteredProducts
@@ -49,19 +132,19 @@ func paginate(products []*Product, pag Pagination) *PaginatedProducts {
 			nil,
 		)
 	}
-	to := pag.Limit
-	if len(products) < pag.Offset+to {
+	to := pag.Offset + pag.Limit
+	if len(products) < to {
 		to = len(products)
 	}
 
 	return NewPaginatedProducts(
 		PaginationMeta{Total: len(products), Pagination: pag},
-		products[pag.Offset:to],
+		append([]*Product(nil), products[pag.Offset:to]...),
 	)
 }
 
-func NewProductRepo(p []*Product) *ProductRepo {
-	return &ProductRepo{p}
+func NewProductRepo(p []*Product, opts ...Option) *ProductRepo {
+	return &ProductRepo{products: append([]*Product(nil), p...), outbox: newOptions(opts).outbox}
 }
 
 func filterByCategory(products []*Product, cat Category) []*Product {
@@ -83,3 +166,13 @@ func filterByPriceLessThan(products []*Product, price Price) []*Product {
 	}
 	return filtered
 }
+
+func filterBySKU(products []*Product, sku SKU) []*Product {
+	var filtered []*Product
+	for _, p := range products {
+		if p.SKU == sku {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,127 @@
+package inmem_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductRepo_ListFiltered(t *testing.T) {
+	repo := inmem.NewProductRepo([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+		catalog.NewProduct("000002", "BV Lean leather ankle boots", "boots", 99000),
+		catalog.NewProduct("000003", "Ashlington leather ankle boots", "boots", 71000),
+		catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+	})
+	pag, _ := catalog.NewPagination(5, 0)
+
+	tests := map[string]struct {
+		filters []catalog.Filter
+		want    []catalog.SKU
+	}{
+		"Without filters":   {want: []catalog.SKU{"000001", "000002", "000003", "000004"}},
+		"By category":       {filters: []catalog.Filter{catalog.NewCategoryFilter("boots")}, want: []catalog.SKU{"000001", "000002", "000003"}},
+		"By price, at most": {filters: []catalog.Filter{catalog.NewPriceLessThanFilter(79500)}, want: []catalog.SKU{"000003", "000004"}},
+		"Matching every filter once": {
+			filters: []catalog.Filter{catalog.NewCategoryFilter("boots"), catalog.NewPriceLessThanFilter(89000)},
+			want:    []catalog.SKU{"000001", "000003"},
+		},
+		"Matching none": {
+			filters: []catalog.Filter{catalog.NewCategoryFilter("sandals"), catalog.NewPriceLessThanFilter(71000)},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			products, err := repo.List(context.Background(), catalog.NewSearchCriteria(pag, tc.filters))
+
+			assert.NoError(t, err)
+			var got []catalog.SKU
+			for _, p := range products.Items() {
+				got = append(got, p.SKU)
+			}
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, len(tc.want), products.Meta.Total)
+		})
+	}
+}
+
+func TestProductRepo_ConcurrentListAndSave(t *testing.T) {
+	repo := inmem.NewProductRepo([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+	})
+	pag, _ := catalog.NewPagination(500, 0)
+	search := catalog.NewSearchCriteria(pag, []catalog.Filter{catalog.NewCategoryFilter("boots")})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				sku := catalog.SKU(fmt.Sprintf("w%d-%03d", w, i))
+				assert.NoError(t, repo.Save([]*catalog.Product{catalog.NewProduct(sku, "Boots", "boots", catalog.Price(i))}))
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				products, err := repo.List(context.Background(), search)
+				assert.NoError(t, err)
+				assert.Equal(t, products.Meta.Total, len(products.Items()))
+				_ = repo.All()
+				_ = repo.Version()
+			}
+		}()
+	}
+	wg.Wait()
+
+	products, _ := repo.List(context.Background(), search)
+	assert.Equal(t, 1+4*50, products.Meta.Total)
+}
+
+func TestProductRepo_ListReturnsACopy(t *testing.T) {
+	repo := inmem.NewProductRepo([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+	})
+	pag, _ := catalog.NewPagination(5, 0)
+
+	products, _ := repo.List(context.Background(), catalog.NewSearchCriteria(pag, nil))
+	_ = repo.ReplaceAll([]*catalog.Product{catalog.NewProduct("000002", "AA hat", "hats", 72000)})
+
+	assert.Equal(t, catalog.SKU("000001"), products.Items()[0].SKU)
+}
+
+func TestProductRepo_Events(t *testing.T) {
+	outbox := inmem.NewOutbox()
+	repo := inmem.NewProductRepo(nil, inmem.WithOutbox(outbox))
+	boots := catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)
+
+	_ = repo.Save([]*catalog.Product{boots})
+	_ = repo.Save([]*catalog.Product{boots})
+	_ = repo.ReplaceAll([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 79000),
+		catalog.NewProduct("000002", "AA hat", "hats", 72000),
+	})
+
+	pending, _ := outbox.Pending(context.Background(), 10)
+	var got []string
+	for _, e := range pending {
+		got = append(got, fmt.Sprintf("%d %s %s", e.ID, e.Type, e.SKU))
+	}
+	assert.Equal(t, []string{
+		"1 product.created 000001",
+		"2 product.price_changed 000001",
+		"3 product.created 000002",
+	}, got)
+
+	_ = outbox.Ack(context.Background(), 2)
+	pending, _ = outbox.Pending(context.Background(), 10)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, uint64(3), pending[0].ID)
+}
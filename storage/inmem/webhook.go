@@ -0,0 +1,143 @@
+package inmem
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/webhooks"
+)
+
+type WebhookRepo struct {
+	mu            sync.RWMutex
+	subscriptions []webhooks.Subscription
+	deadLetters   []webhooks.DeadLetter
+	prices        map[SKU]DiscountedPrice
+	pending       []webhooks.Delivery
+}
+
+func (r *WebhookRepo) Add(ctx context.Context, s webhooks.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions = append(r.subscriptions, s)
+	return nil
+}
+
+func (r *WebhookRepo) Remove(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, s := range r.subscriptions {
+		if s.ID == id {
+			r.subscriptions = append(r.subscriptions[:i:i], r.subscriptions[i+1:]...)
+			r.dropPending(func(d webhooks.Delivery) bool { return d.SubscriptionID == id })
+			return nil
+		}
+	}
+	return webhooks.ErrSubscriptionNotFound
+}
+
+func (r *WebhookRepo) List(ctx context.Context) ([]webhooks.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]webhooks.Subscription(nil), r.subscriptions...), nil
+}
+
+func (r *WebhookRepo) AddDeadLetter(ctx context.Context, d webhooks.DeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deadLetters = append(r.deadLetters, d)
+	r.dropPending(func(p webhooks.Delivery) bool {
+		return p.SubscriptionID == d.SubscriptionID && p.Payload.ID == d.Payload.ID
+	})
+	return nil
+}
+
+func (r *WebhookRepo) DeadLetters(ctx context.Context) ([]webhooks.DeadLetter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]webhooks.DeadLetter(nil), r.deadLetters...), nil
+}
+
+func (r *WebhookRepo) Prices(ctx context.Context) (map[SKU]DiscountedPrice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prices := make(map[SKU]DiscountedPrice, len(r.prices))
+	for sku, p := range r.prices {
+		prices[sku] = p
+	}
+	return prices, nil
+}
+
+func (r *WebhookRepo) SavePrices(ctx context.Context, prices map[SKU]DiscountedPrice, pending []webhooks.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for sku, p := range prices {
+		r.prices[sku] = p
+	}
+	r.pending = append(r.pending, pending...)
+	return nil
+}
+
+func (r *WebhookRepo) Pending(ctx context.Context) ([]webhooks.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]webhooks.Delivery(nil), r.pending...), nil
+}
+
+func (r *WebhookRepo) Delivered(ctx context.Context, subscriptionID, payloadID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropPending(func(d webhooks.Delivery) bool {
+		return d.SubscriptionID == subscriptionID && d.Payload.ID == payloadID
+	})
+	return nil
+}
+
+func (r *WebhookRepo) dropPending(match func(d webhooks.Delivery) bool) {
+	kept := r.pending[:0]
+	for _, d := range r.pending {
+		if !match(d) {
+			kept = append(kept, d)
+		}
+	}
+	r.pending = kept
+}
+
+// Restore loads the subscriptions, dead letters, prices and pending
+// deliveries of a previous run.
+func (r *WebhookRepo) Restore(subs []webhooks.Subscription, letters []webhooks.DeadLetter, prices map[SKU]DiscountedPrice, pending []webhooks.Delivery) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions = append([]webhooks.Subscription(nil), subs...)
+	r.deadLetters = append([]webhooks.DeadLetter(nil), letters...)
+	r.prices = make(map[SKU]DiscountedPrice, len(prices))
+	for sku, p := range prices {
+		r.prices[sku] = p
+	}
+	r.pending = append([]webhooks.Delivery(nil), pending...)
+}
+
+// Snapshot returns the subscriptions, dead letters, prices and pending
+// deliveries.
+func (r *WebhookRepo) Snapshot() ([]webhooks.Subscription, []webhooks.DeadLetter, map[SKU]DiscountedPrice, []webhooks.Delivery) {
+	prices, _ := r.Prices(context.Background())
+	pending, _ := r.Pending(context.Background())
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]webhooks.Subscription(nil), r.subscriptions...), append([]webhooks.DeadLetter(nil), r.deadLetters...), prices, pending
+}
+
+func NewWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{prices: make(map[SKU]DiscountedPrice)}
+}
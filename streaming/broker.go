@@ -0,0 +1,191 @@
+package streaming
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+)
+
+const (
+	defaultHistory = 1000
+	defaultBuffer  = 64
+)
+
+// Update is a discounted product re-priced after a catalog change. IDs grow
+// with every update, so clients resume after the last one they received, and
+// start again in every broker, told apart by its epoch.
+type Update struct {
+	ID      uint64
+	Product catalog.DiscountedProduct
+}
+
+// Filter selects the updates of a category or a SKU, an empty filter
+// selecting every update.
+type Filter struct {
+	Category catalog.Category
+	SKU      catalog.SKU
+}
+
+func (f Filter) matches(p catalog.DiscountedProduct) bool {
+	return (f.Category == "" || f.Category == p.Category) && (f.SKU == "" || f.SKU == p.SKU)
+}
+
+// Subscription receives the updates matching its filter on C, after the
+// replayed ones. C is closed when the subscriber falls behind, so it resumes
+// from the last update it received.
+type Subscription struct {
+	Replay []Update
+	C      <-chan Update
+	c      chan Update
+	filter Filter
+}
+
+// Broker is an events.Publisher: every catalog event re-prices the products it
+// affects and broadcasts them to the subscriptions, keeping the last updates
+// to replay on resume.
+type Broker struct {
+	lister  listing.ProductLister
+	epoch   string
+	history int
+	buffer  int
+
+	mu      sync.Mutex
+	lastID  uint64
+	updates []Update
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+type Option func(b *Broker)
+
+// WithHistory sets the number of updates kept to replay on resume.
+func WithHistory(n int) Option {
+	return func(b *Broker) {
+		b.history = n
+	}
+}
+
+// WithBuffer sets the updates a subscription holds before it is dropped.
+func WithBuffer(n int) Option {
+	return func(b *Broker) {
+		b.buffer = n
+	}
+}
+
+// WithEpoch sets the epoch of the broker, random by default.
+func WithEpoch(epoch string) Option {
+	return func(b *Broker) {
+		b.epoch = epoch
+	}
+}
+
+func NewBroker(pl listing.ProductLister, opts ...Option) *Broker {
+	b := &Broker{lister: pl, epoch: newEpoch(), history: defaultHistory, buffer: defaultBuffer, subs: make(map[*Subscription]struct{})}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// newEpoch identifies the broker, since update IDs start again on restart.
+func newEpoch() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Epoch tells the update IDs of the broker apart from the ones of a previous
+// run.
+func (b *Broker) Epoch() string {
+	return b.epoch
+}
+
+func (b *Broker) Publish(ctx context.Context, e catalog.Event) error {
+	var filter catalog.Filter
+	switch {
+	case e.SKU != "":
+		filter = catalog.NewSKUFilter(e.SKU)
+	case e.Category != "":
+		filter = catalog.NewCategoryFilter(e.Category)
+	default:
+		return nil
+	}
+	products, err := listing.ListAll(ctx, b.lister, []catalog.Filter{filter})
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, p := range products {
+		b.lastID++
+		u := Update{ID: b.lastID, Product: *p}
+		b.updates = append(b.updates, u)
+		for sub := range b.subs {
+			if !sub.filter.matches(u.Product) {
+				continue
+			}
+			select {
+			case sub.c <- u:
+			default:
+				b.drop(sub)
+			}
+		}
+	}
+	if over := len(b.updates) - b.history; over > 0 {
+		b.updates = append([]Update(nil), b.updates[over:]...)
+	}
+	return nil
+}
+
+// Subscribe streams the updates matching f, replaying the kept ones after
+// lastID.
+func (b *Broker) Subscribe(f Filter, lastID uint64) *Subscription {
+	c := make(chan Update, b.buffer)
+	sub := &Subscription{C: c, c: c, filter: f}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, u := range b.updates {
+		if u.ID > lastID && f.matches(u.Product) {
+			sub.Replay = append(sub.Replay, u)
+		}
+	}
+	if b.closed {
+		close(c)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close ends every subscription, and the ones made afterwards, so streams do
+// not hold the server shutdown.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+	return nil
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(sub)
+}
+
+func (b *Broker) drop(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+}
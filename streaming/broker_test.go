@@ -0,0 +1,106 @@
+package streaming_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/streaming"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBroker(opts ...streaming.Option) *streaming.Broker {
+	products := inmem.NewProductRepo([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+		catalog.NewProduct("000002", "BV Lean leather ankle boots", "boots", 99000),
+		catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+	})
+	discounts := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	return streaming.NewBroker(listing.NewProductLister(products, pricingacl.NewCalculater(discounts)), opts...)
+}
+
+func skus(updates []streaming.Update) []catalog.SKU {
+	var skus []catalog.SKU
+	for _, u := range updates {
+		skus = append(skus, u.Product.SKU)
+	}
+	return skus
+}
+
+func receive(sub *streaming.Subscription) []streaming.Update {
+	var updates []streaming.Update
+	for {
+		select {
+		case u, ok := <-sub.C:
+			if !ok {
+				return updates
+			}
+			updates = append(updates, u)
+		default:
+			return updates
+		}
+	}
+}
+
+func TestBroker_Publish(t *testing.T) {
+	ctx := context.Background()
+	b := newBroker()
+	all := b.Subscribe(streaming.Filter{}, 0)
+	boots := b.Subscribe(streaming.Filter{Category: "boots"}, 0)
+	product := b.Subscribe(streaming.Filter{SKU: "000004"}, 0)
+
+	require.NoError(t, b.Publish(ctx, catalog.NewDiscountAppliedEvent(catalog.NewCategoryDiscount("boots", 30))))
+	require.NoError(t, b.Publish(ctx, catalog.NewPriceChangedEvent(catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500), 89000)))
+
+	assert.Equal(t, []catalog.SKU{"000001", "000002", "000004"}, skus(receive(all)))
+	assert.Equal(t, []catalog.SKU{"000001", "000002"}, skus(receive(boots)))
+	updates := receive(product)
+	require.Len(t, updates, 1)
+	assert.Equal(t, uint64(3), updates[0].ID)
+	assert.Equal(t, *catalog.NewDiscountedProduct("000004", "Naima embellished suede sandals", "sandals", *catalog.NewDiscountedPrice(79500, nil)), updates[0].Product)
+
+	t.Run("Resume after the last update", func(t *testing.T) {
+		sub := b.Subscribe(streaming.Filter{}, 1)
+		defer b.Unsubscribe(sub)
+		assert.Equal(t, []catalog.SKU{"000002", "000004"}, skus(sub.Replay))
+	})
+}
+
+func TestBroker_limits(t *testing.T) {
+	ctx := context.Background()
+	b := newBroker(streaming.WithHistory(2), streaming.WithBuffer(1))
+	slow := b.Subscribe(streaming.Filter{}, 0)
+
+	require.NoError(t, b.Publish(ctx, catalog.NewDiscountAppliedEvent(catalog.NewCategoryDiscount("boots", 30))))
+	require.NoError(t, b.Publish(ctx, catalog.NewDiscountExpiredEvent(catalog.NewProductDiscount("000004", 10))))
+
+	assert.Equal(t, []catalog.SKU{"000001"}, skus(receive(slow)))
+	_, open := <-slow.C
+	assert.False(t, open, "slow subscriber is dropped")
+
+	resumed := b.Subscribe(streaming.Filter{}, 0)
+	assert.Equal(t, []catalog.SKU{"000002", "000004"}, skus(resumed.Replay))
+	b.Unsubscribe(resumed)
+	b.Unsubscribe(slow)
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := newBroker()
+	sub := b.Subscribe(streaming.Filter{}, 0)
+
+	require.NoError(t, b.Close())
+
+	_, open := <-sub.C
+	assert.False(t, open)
+	_, open = <-b.Subscribe(streaming.Filter{}, 0).C
+	assert.False(t, open)
+}
+
+func TestBroker_Epoch(t *testing.T) {
+	assert.NotEqual(t, newBroker().Epoch(), newBroker().Epoch(), "every broker has its own epoch")
+	assert.Equal(t, "e1", newBroker(streaming.WithEpoch("e1")).Epoch())
+}
@@ -1,13 +1,17 @@
 package stub
 
-import . "github.com/amelendres/go-catalog/catalog"
+import (
+	"context"
+
+	. "github.com/amelendres/go-catalog/catalog"
+)
 
 type StubDiscountRepo struct {
 	discounts []Discount
 	wantErr   error
 }
 
-func (r *StubDiscountRepo) Find(search SearchCriteria) (discounts []Discount, err error) {
+func (r *StubDiscountRepo) Find(ctx context.Context, search SearchCriteria) (discounts []Discount, err error) {
 	if r.wantErr != nil {
 		return nil, r.wantErr
 	}
@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+
+	. "github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type productLister struct {
+	next   listing.ProductLister
+	tracer *Tracer
+}
+
+func (t *Tracer) ProductLister(next listing.ProductLister) listing.ProductLister {
+	return &productLister{next, t}
+}
+
+func (l *productLister) List(ctx context.Context, search SearchCriteria) (products *PaginatedDiscountedProducts, err error) {
+	ctx, span := l.tracer.Start(ctx, "listing.ProductLister.List", trace.WithAttributes(criteriaAttributes(search)...))
+	defer func() { end(span, err) }()
+
+	products, err = l.next.List(ctx, search)
+	if err == nil {
+		span.SetAttributes(attribute.Int("catalog.total", products.MetaData().Total))
+	}
+	return products, err
+}
+
+type calculater struct {
+	next   Calculater
+	tracer *Tracer
+}
+
+func (t *Tracer) Calculater(next Calculater) Calculater {
+	return &calculater{next, t}
+}
+
+func (c *calculater) Calculate(ctx context.Context, p Product) (price *DiscountedPrice, err error) {
+	ctx, span := c.tracer.Start(ctx, "catalog.Calculater.Calculate", trace.WithAttributes(
+		attribute.String("catalog.sku", string(p.SKU)),
+		attribute.String("catalog.category", string(p.Category)),
+	))
+	defer func() { end(span, err) }()
+
+	price, err = c.next.Calculate(ctx, p)
+	if err == nil && price.DiscountPercentage != nil {
+		span.SetAttributes(attribute.Int("pricing.discount_percentage", int(*price.DiscountPercentage)))
+	}
+	return price, err
+}
+
+type productRepository struct {
+	next   ProductRepository
+	tracer *Tracer
+}
+
+func (t *Tracer) ProductRepository(next ProductRepository) ProductRepository {
+	return &productRepository{next, t}
+}
+
+func (r *productRepository) List(ctx context.Context, search SearchCriteria) (products *PaginatedProducts, err error) {
+	ctx, span := r.tracer.Start(ctx, "catalog.ProductRepository.List", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(criteriaAttributes(search)...))
+	defer func() { end(span, err) }()
+
+	return r.next.List(ctx, search)
+}
+
+type discountRepository struct {
+	next   DiscountRepository
+	tracer *Tracer
+}
+
+func (t *Tracer) DiscountRepository(next DiscountRepository) DiscountRepository {
+	return &discountRepository{next, t}
+}
+
+func (r *discountRepository) Find(ctx context.Context, search SearchCriteria) (discounts []Discount, err error) {
+	ctx, span := r.tracer.Start(ctx, "catalog.DiscountRepository.Find", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(criteriaAttributes(search)...))
+	defer func() { end(span, err) }()
+
+	return r.next.Find(ctx, search)
+}
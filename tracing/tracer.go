@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/amelendres/go-catalog/catalog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/amelendres/go-catalog"
+
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// New builds a Tracer propagating W3C trace context and baggage.
+func New(tp trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer:     tp.Tracer(instrumentationName),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name, opts...)
+}
+
+// Extract returns ctx carrying the remote span context found in the headers.
+func (t *Tracer) Extract(ctx context.Context, h http.Header) context.Context {
+	return t.propagator.Extract(ctx, propagation.HeaderCarrier(h))
+}
+
+// Inject writes the span context of ctx into the headers.
+func (t *Tracer) Inject(ctx context.Context, h http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func criteriaAttributes(search SearchCriteria) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if pag := search.Pagination(); pag != nil {
+		attrs = append(attrs, attribute.Int("catalog.limit", pag.Limit), attribute.Int("catalog.offset", pag.Offset))
+	}
+	for _, f := range search.Filters() {
+		switch filter := f.(type) {
+		case CategoryFilter:
+			attrs = append(attrs, attribute.String("catalog.category", string(filter.Value())))
+		case PriceLessThanFilter:
+			attrs = append(attrs, attribute.Int("catalog.price_less_than", int(filter.Value())))
+		case SKUFilter:
+			attrs = append(attrs, attribute.String("catalog.sku", string(filter.Value())))
+		}
+	}
+	return attrs
+}
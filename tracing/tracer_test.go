@@ -0,0 +1,93 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/http/rest"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/tracing"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	givenTraceID    = "4bf92f3577b34da6a3ce929d0e0e4736"
+	givenParentSpan = "00f067aa0ba902b7"
+)
+
+var givenProducts = []*catalog.Product{
+	catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+	catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+}
+
+func TestTracer_CatalogServer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := tracing.New(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	discountRepo := inmem.NewDiscountRepo([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	pricingCalculater := tracer.Calculater(pricingacl.NewCalculater(tracer.DiscountRepository(discountRepo)))
+	productLister := tracer.ProductLister(listing.NewProductLister(tracer.ProductRepository(inmem.NewProductRepo(givenProducts)), pricingCalculater))
+	catalogService := rest.NewCatalogServer(productLister, rest.WithTracer(tracer))
+
+	req := httptest.NewRequest(http.MethodGet, "/products?limit=5", nil)
+	req.Header.Set("traceparent", "00-"+givenTraceID+"-"+givenParentSpan+"-01")
+	catalogService.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name())
+		assert.Equal(t, givenTraceID, s.SpanContext().TraceID().String(), s.Name())
+	}
+	assert.ElementsMatch(t, []string{
+		"GET /products",
+		"listing.ProductLister.List",
+		"catalog.ProductRepository.List",
+		"catalog.Calculater.Calculate",
+		"catalog.DiscountRepository.Find",
+		"catalog.Calculater.Calculate",
+		"catalog.DiscountRepository.Find",
+	}, names)
+
+	server := spans[len(spans)-1]
+	assert.Equal(t, "GET /products", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, givenParentSpan, server.Parent().SpanID().String())
+	assert.True(t, server.Parent().IsRemote())
+}
+
+func TestNewProvider_OTLPExporter(t *testing.T) {
+	var received int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			atomic.AddInt32(&received, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	tp, err := tracing.NewProvider(context.Background(), tracing.OTLPExporter, strings.TrimPrefix(collector.URL, "http://"), nil)
+	assert.NoError(t, err)
+
+	_, span := tracing.New(tp).Start(context.Background(), "test")
+	span.End()
+	assert.NoError(t, tp.Shutdown(context.Background()))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&received))
+}
+
+func TestNewProvider_UnknownExporter(t *testing.T) {
+	_, err := tracing.NewProvider(context.Background(), "zipkin", "", nil)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	SignatureHeader = "X-Catalog-Signature"
+	EventHeader     = "X-Catalog-Event"
+	DeliveryHeader  = "X-Catalog-Delivery"
+	AttemptHeader   = "X-Catalog-Attempt"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature header value of body, the hex HMAC-SHA256 of the
+// body keyed with the subscription secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body, for receivers.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// queue delivers the payloads of a subscription one at a time, in order.
+type queue struct {
+	sub      Subscription
+	payloads chan Payload
+}
+
+// errStopped is returned by send once the service is closed.
+var errStopped = errors.New("delivery stopped on shutdown")
+
+// deliver queues the payloads of the subscription, dead-lettering the ones
+// that do not fit in its queue. The caller holds s.mu, so the payloads are
+// queued in the order they were saved. Nothing is queued once the service is
+// closed, the payloads staying pending.
+func (s *Service) deliver(sub Subscription, payloads []Payload) {
+	if s.closed {
+		return
+	}
+	q, ok := s.queues[sub.ID]
+	if !ok {
+		q = &queue{sub: sub, payloads: make(chan Payload, s.queueSize)}
+		s.queues[sub.ID] = q
+		s.deliveries.Add(1)
+		go s.run(q)
+	}
+	for _, p := range payloads {
+		s.pending.Add(1)
+		select {
+		case q.payloads <- p:
+		default:
+			s.pending.Done()
+			s.deadLetter(sub, p, 0, fmt.Errorf("not delivered, %d payloads are queued", s.queueSize))
+		}
+	}
+}
+
+// run sends the payloads of q until it is closed, dead-lettering the ones
+// that fail every attempt and leaving pending the ones stopped on shutdown.
+func (s *Service) run(q *queue) {
+	defer s.deliveries.Done()
+	for p := range q.payloads {
+		attempts, err := s.send(q.sub, p)
+		switch {
+		case errors.Is(err, errStopped):
+		case err != nil:
+			s.deadLetter(q.sub, p, attempts, err)
+		default:
+			if err := s.repo.Delivered(context.Background(), q.sub.ID, p.ID); err != nil {
+				s.logger.Error("could not record webhook delivery", zap.String("delivery", p.ID), zap.Error(err))
+			}
+		}
+		s.pending.Done()
+	}
+}
+
+func (s *Service) deadLetter(sub Subscription, p Payload, attempts int, err error) {
+	s.logger.Warn("could not deliver webhook",
+		zap.String("subscription", sub.ID),
+		zap.String("delivery", p.ID),
+		zap.Int("attempts", attempts),
+		zap.Error(err),
+	)
+	d := DeadLetter{SubscriptionID: sub.ID, URL: sub.URL, Payload: p, Attempts: attempts, Error: err.Error(), FailedAt: s.now().UTC()}
+	if err := s.repo.AddDeadLetter(context.Background(), d); err != nil {
+		s.logger.Error("could not dead-letter webhook", zap.String("delivery", p.ID), zap.Error(err))
+	}
+}
+
+// send posts the payload until it is accepted or the attempts are exhausted,
+// waiting an exponential backoff between attempts, and stops with errStopped
+// once the service is closed.
+func (s *Service) send(sub Subscription, p Payload) (attempts int, err error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return 0, err
+	}
+	wait := s.backoff
+	for attempts = 1; ; attempts++ {
+		if s.stop.Err() != nil {
+			return attempts - 1, errStopped
+		}
+		err = s.post(sub, p, body, attempts)
+		if err == nil || (attempts >= s.maxAttempts && s.stop.Err() == nil) {
+			return attempts, err
+		}
+		select {
+		case <-time.After(wait):
+			wait *= 2
+		case <-s.stop.Done():
+		}
+	}
+}
+
+func (s *Service) post(sub Subscription, p Payload, body []byte, attempt int) error {
+	req, err := http.NewRequestWithContext(s.stop, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+	req.Header.Set(EventHeader, p.Type)
+	req.Header.Set(DeliveryHeader, p.ID)
+	req.Header.Set(AttemptHeader, strconv.Itoa(attempt))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package webhooks
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// isPublic reports whether ip is reachable from the internet, rather than
+// the loopback, link-local or private address of an internal service.
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsPrivate() && !ip.IsUnspecified() && !ip.IsMulticast()
+}
+
+// checkHost rejects the hosts naming a non public address, the names
+// resolved on delivery being checked by the client.
+func checkHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%s is not a public host", host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublic(ip) {
+		return fmt.Errorf("%s is not a public address", host)
+	}
+	return nil
+}
+
+// newClient returns the delivery client. Unless allowPrivate, it connects
+// directly, without proxy, and only to public addresses, checked once
+// resolved so neither DNS answers nor redirects point it at internal
+// services.
+func newClient(allowPrivate bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{
+			Timeout: defaultTimeout,
+			Control: func(network, address string, c syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+					return fmt.Errorf("%s is not a public address", host)
+				}
+				return nil
+			},
+		}
+		transport.Proxy = nil
+		transport.DialContext = dialer.DialContext
+	}
+	return &http.Client{Timeout: defaultTimeout, Transport: transport}
+}
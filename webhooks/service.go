@@ -0,0 +1,305 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/listing"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBackoff     = time.Second
+	defaultTimeout     = 5 * time.Second
+	defaultQueueSize   = 1000
+)
+
+// Service notifies the subscriptions of the final price changes. It is an
+// events.Publisher: every catalog event re-prices the products it affects and
+// the ones whose final price changed are delivered, asynchronously and in
+// order, to every subscription.
+type Service struct {
+	repo         Repository
+	lister       listing.ProductLister
+	client       *http.Client
+	allowPrivate bool
+	maxAttempts  int
+	backoff      time.Duration
+	queueSize    int
+	now          func() time.Time
+	logger       *zap.Logger
+
+	mu     sync.Mutex
+	prices map[catalog.SKU]catalog.DiscountedPrice
+	queues map[string]*queue
+	closed bool
+
+	// deliveries tracks the queues, pending the payloads they hold.
+	deliveries sync.WaitGroup
+	pending    sync.WaitGroup
+	// stop is canceled on Close, aborting the posts in flight.
+	stop   context.Context
+	cancel context.CancelFunc
+}
+
+type Option func(s *Service)
+
+// WithClient delivers through c, which replaces the check of the addresses
+// the default client connects to.
+func WithClient(c *http.Client) Option {
+	return func(s *Service) {
+		s.client = c
+	}
+}
+
+// WithPrivateNetworks allows subscribing loopback, link-local and private
+// addresses, for receivers within the network of the catalog.
+func WithPrivateNetworks() Option {
+	return func(s *Service) {
+		s.allowPrivate = true
+	}
+}
+
+// WithQueueSize sets the payloads a subscription holds before the next ones
+// are dead-lettered.
+func WithQueueSize(n int) Option {
+	return func(s *Service) {
+		s.queueSize = n
+	}
+}
+
+// WithRetries sets the delivery attempts of a payload and the wait before the
+// first retry, doubled on every following one.
+func WithRetries(maxAttempts int, backoff time.Duration) Option {
+	return func(s *Service) {
+		s.maxAttempts = maxAttempts
+		s.backoff = backoff
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
+}
+
+func WithLogger(l *zap.Logger) Option {
+	return func(s *Service) {
+		s.logger = l
+	}
+}
+
+func NewService(repo Repository, pl listing.ProductLister, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		lister:      pl,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		queueSize:   defaultQueueSize,
+		now:         time.Now,
+		logger:      zap.NewNop(),
+		prices:      make(map[catalog.SKU]catalog.DiscountedPrice),
+		queues:      make(map[string]*queue),
+	}
+	s.stop, s.cancel = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.client == nil {
+		s.client = newClient(s.allowPrivate)
+	}
+	return s
+}
+
+// Register subscribes url, generating its secret when empty.
+func (s *Service) Register(ctx context.Context, rawURL, secret string) (*Subscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, catalog.NewInvalidArgumentError("url must be an absolute http or https URL, got %q", rawURL)
+	}
+	if !s.allowPrivate {
+		if err := checkHost(u.Hostname()); err != nil {
+			return nil, catalog.NewInvalidArgumentError("url must be a public address, got %q", rawURL)
+		}
+	}
+	if secret == "" {
+		secret = randomID(32)
+	}
+	sub := Subscription{ID: randomID(16), URL: rawURL, Secret: secret, CreatedAt: s.now().UTC()}
+	if err := s.repo.Add(ctx, sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Unregister removes the subscription, still delivering the payloads it was
+// queued.
+func (s *Service) Unregister(ctx context.Context, id string) error {
+	if err := s.repo.Remove(ctx, id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if q, ok := s.queues[id]; ok {
+		close(q.payloads)
+		delete(s.queues, id)
+	}
+	return nil
+}
+
+// Subscriptions lists the subscriptions without their secrets.
+func (s *Service) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	subs, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+func (s *Service) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	return s.repo.DeadLetters(ctx)
+}
+
+// Prime loads the prices the subscriptions were last notified of, queues the
+// deliveries a previous run left pending and delivers the changes since. The
+// prices of the products first seen are recorded as the before of their
+// first change.
+func (s *Service) Prime(ctx context.Context) error {
+	prices, err := s.repo.Prices(ctx)
+	if err != nil {
+		return err
+	}
+	pending, err := s.repo.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	subs, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]Subscription, len(subs))
+	for _, sub := range subs {
+		byID[sub.ID] = sub
+	}
+
+	s.mu.Lock()
+	for sku, p := range prices {
+		s.prices[sku] = p
+	}
+	for _, d := range pending {
+		if sub, ok := byID[d.SubscriptionID]; ok {
+			s.deliver(sub, []Payload{d.Payload})
+		}
+	}
+	s.mu.Unlock()
+
+	return s.reprice(ctx, nil)
+}
+
+func (s *Service) Publish(ctx context.Context, e catalog.Event) error {
+	var filter catalog.Filter
+	switch {
+	case e.SKU != "":
+		filter = catalog.NewSKUFilter(e.SKU)
+	case e.Category != "":
+		filter = catalog.NewCategoryFilter(e.Category)
+	default:
+		return nil
+	}
+	return s.reprice(ctx, []catalog.Filter{filter})
+}
+
+// reprice lists the filtered products and queues the payloads of the ones
+// whose final price changed to every subscription. Their prices are recorded
+// along with the pending deliveries, so the ones not delivered yet survive a
+// restart.
+func (s *Service) reprice(ctx context.Context, filters []catalog.Filter) error {
+	products, err := listing.ListAll(ctx, s.lister, filters)
+	if err != nil {
+		return err
+	}
+	subs, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var payloads []Payload
+	changed := make(map[catalog.SKU]catalog.DiscountedPrice)
+	for _, p := range products {
+		before, known := s.prices[p.SKU]
+		if known && before.Final == p.Price.Final {
+			continue
+		}
+		changed[p.SKU] = p.Price
+		if known {
+			payloads = append(payloads, Payload{
+				ID:         randomID(16),
+				Type:       PriceChangedEvent,
+				OccurredAt: s.now().UTC(),
+				SKU:        p.SKU,
+				Before:     before,
+				After:      p.Price,
+			})
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	var pending []Delivery
+	for _, sub := range subs {
+		for _, p := range payloads {
+			pending = append(pending, Delivery{SubscriptionID: sub.ID, Payload: p})
+		}
+	}
+	// a failed write is retried with the event, nothing being queued.
+	if err := s.repo.SavePrices(ctx, changed, pending); err != nil {
+		return err
+	}
+	for sku, p := range changed {
+		s.prices[sku] = p
+	}
+	for _, sub := range subs {
+		s.deliver(sub, payloads)
+	}
+	return nil
+}
+
+// Close stops delivering, aborting the posts in flight, and waits for the
+// queues to stop. The deliveries not made are left pending, for the next
+// Prime.
+func (s *Service) Close() error {
+	s.cancel()
+	s.mu.Lock()
+	s.closed = true
+	for id, q := range s.queues {
+		close(q.payloads)
+		delete(s.queues, id)
+	}
+	s.mu.Unlock()
+	s.deliveries.Wait()
+	return nil
+}
+
+// Wait blocks until the queued deliveries succeed or are dead-lettered.
+func (s *Service) Wait() {
+	s.pending.Wait()
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,327 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+	"github.com/amelendres/go-catalog/events"
+	"github.com/amelendres/go-catalog/listing"
+	"github.com/amelendres/go-catalog/pricingacl"
+	"github.com/amelendres/go-catalog/storage/inmem"
+	"github.com/amelendres/go-catalog/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the deliveries it accepts, failing the first ones.
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	payloads []webhooks.Payload
+	headers  []http.Header
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.attempts++
+	if rc.failures > 0 {
+		rc.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var p webhooks.Payload
+	_ = json.Unmarshal(body, &p)
+	rc.payloads = append(rc.payloads, p)
+	rc.headers = append(rc.headers, r.Header.Clone())
+	rc.bodies = append(rc.bodies, body)
+}
+
+type fixture struct {
+	service   *webhooks.Service
+	outbox    *inmem.Outbox
+	products  *inmem.ProductRepo
+	discounts *inmem.DiscountRepo
+}
+
+func newFixture(t *testing.T, opts ...webhooks.Option) *fixture {
+	t.Helper()
+	outbox := inmem.NewOutbox()
+	products := inmem.NewProductRepo([]*catalog.Product{
+		catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000),
+		catalog.NewProduct("000002", "BV Lean leather ankle boots", "boots", 99000),
+		catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500),
+	}, inmem.WithOutbox(outbox))
+	discounts := inmem.NewDiscountRepo(nil, inmem.WithOutbox(outbox))
+	lister := listing.NewProductLister(products, pricingacl.NewCalculater(discounts))
+
+	opts = append([]webhooks.Option{webhooks.WithRetries(3, time.Millisecond)}, opts...)
+	service := webhooks.NewService(inmem.NewWebhookRepo(), lister, opts...)
+	require.NoError(t, service.Prime(context.Background()))
+	t.Cleanup(func() { _ = service.Close() })
+	return &fixture{service, outbox, products, discounts}
+}
+
+func (f *fixture) dispatch(t *testing.T) {
+	t.Helper()
+	_, err := events.NewDispatcher(f.outbox, f.service).Dispatch(context.Background())
+	require.NoError(t, err)
+	f.service.Wait()
+}
+
+func TestService_Publish(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, webhooks.WithPrivateNetworks())
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	sub, err := f.service.Register(ctx, srv.URL, "s3cr3t")
+	require.NoError(t, err)
+
+	_ = f.discounts.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	_ = f.products.Save([]*catalog.Product{catalog.NewProduct("000004", "Naima embellished suede sandals", "sandals", 79500)})
+	f.dispatch(t)
+
+	require.Len(t, rc.payloads, 2)
+	percentage := catalog.DiscountPercentage(30)
+	assert.Equal(t, catalog.SKU("000001"), rc.payloads[0].SKU)
+	assert.Equal(t, webhooks.PriceChangedEvent, rc.payloads[0].Type)
+	assert.Equal(t, *catalog.NewDiscountedPrice(89000, nil), rc.payloads[0].Before)
+	assert.Equal(t, *catalog.NewDiscountedPrice(89000, &percentage), rc.payloads[0].After)
+	assert.Equal(t, catalog.SKU("000002"), rc.payloads[1].SKU)
+
+	for i, h := range rc.headers {
+		assert.True(t, webhooks.Verify(sub.Secret, rc.bodies[i], h.Get(webhooks.SignatureHeader)))
+		assert.Equal(t, rc.payloads[i].ID, h.Get(webhooks.DeliveryHeader))
+	}
+	assert.False(t, webhooks.Verify("other", rc.bodies[0], rc.headers[0].Get(webhooks.SignatureHeader)))
+
+	t.Run("Unchanged prices are not delivered again", func(t *testing.T) {
+		_ = f.discounts.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+		_ = f.products.Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 79000)})
+		f.dispatch(t)
+
+		require.Len(t, rc.payloads, 3)
+		assert.Equal(t, catalog.Price(62300), rc.payloads[2].Before.Final)
+		assert.Equal(t, catalog.Price(55300), rc.payloads[2].After.Final)
+	})
+
+	t.Run("Unregistered subscriptions are not notified", func(t *testing.T) {
+		require.NoError(t, f.service.Unregister(ctx, sub.ID))
+		_ = f.discounts.ReplaceAll(nil)
+		f.dispatch(t)
+
+		assert.Len(t, rc.payloads, 3)
+		assert.Equal(t, webhooks.ErrSubscriptionNotFound, f.service.Unregister(ctx, sub.ID))
+	})
+}
+
+func TestService_retries(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		failures        int
+		wantAttempts    int
+		wantDelivered   int
+		wantDeadLetters int
+	}{
+		"Delivered after retries": {failures: 2, wantAttempts: 3, wantDelivered: 1},
+		"Dead-lettered":           {failures: 5, wantAttempts: 3, wantDeadLetters: 1},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t, webhooks.WithPrivateNetworks())
+			rc := &receiver{failures: tt.failures}
+			srv := httptest.NewServer(rc)
+			defer srv.Close()
+			_, err := f.service.Register(ctx, srv.URL, "")
+			require.NoError(t, err)
+
+			_ = f.discounts.Save([]catalog.Discount{catalog.NewProductDiscount("000004", 10)})
+			f.dispatch(t)
+
+			assert.Equal(t, tt.wantAttempts, rc.attempts)
+			assert.Len(t, rc.payloads, tt.wantDelivered)
+			letters, err := f.service.DeadLetters(ctx)
+			require.NoError(t, err)
+			require.Len(t, letters, tt.wantDeadLetters)
+			if tt.wantDeadLetters > 0 {
+				assert.Equal(t, srv.URL, letters[0].URL)
+				assert.Equal(t, tt.wantAttempts, letters[0].Attempts)
+				assert.Equal(t, catalog.SKU("000004"), letters[0].Payload.SKU)
+				assert.Contains(t, letters[0].Error, "503")
+			}
+		})
+	}
+}
+
+func TestService_Register(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	for _, url := range []string{
+		"", "partner.example/prices", "ftp://partner.example", "http://",
+		"http://localhost:8080/prices", "http://127.0.0.1/prices", "http://[::1]/prices",
+		"http://10.0.0.7/prices", "http://192.168.1.10/prices", "http://169.254.169.254/latest/meta-data",
+	} {
+		_, err := f.service.Register(ctx, url, "")
+		assert.Equal(t, catalog.InvalidArgumentError, catalog.ErrorCodeOf(err), url)
+	}
+
+	sub, err := f.service.Register(ctx, "https://partner.example/prices", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, sub.Secret)
+
+	subs, err := f.service.Subscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, sub.ID, subs[0].ID)
+	assert.Empty(t, subs[0].Secret)
+}
+
+func TestService_deliversToPublicAddressesOnly(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewWebhookRepo()
+	products := inmem.NewProductRepo([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)})
+	discounts := inmem.NewDiscountRepo(nil)
+	service := webhooks.NewService(repo, listing.NewProductLister(products, pricingacl.NewCalculater(discounts)), webhooks.WithRetries(1, time.Millisecond))
+	defer service.Close()
+	require.NoError(t, service.Prime(ctx))
+
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	// a public host name resolving to the loopback, added past Register.
+	require.NoError(t, repo.Add(ctx, webhooks.Subscription{ID: "internal", URL: srv.URL}))
+
+	_ = discounts.Save([]catalog.Discount{catalog.NewCategoryDiscount("boots", 30)})
+	require.NoError(t, service.Publish(ctx, catalog.Event{Category: "boots"}))
+	service.Wait()
+
+	assert.Zero(t, rc.attempts)
+	letters, _ := service.DeadLetters(ctx)
+	require.Len(t, letters, 1)
+	assert.Contains(t, letters[0].Error, "is not a public address")
+}
+
+func TestService_deliversInOrder(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, webhooks.WithPrivateNetworks())
+	rc := &receiver{failures: 1}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	_, err := f.service.Register(ctx, srv.URL, "")
+	require.NoError(t, err)
+
+	for _, p := range []catalog.Price{80000, 70000, 60000} {
+		_ = f.products.Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", p)})
+		f.dispatch(t)
+	}
+
+	var finals []catalog.Price
+	for _, p := range rc.payloads {
+		finals = append(finals, p.After.Final)
+	}
+	assert.Equal(t, []catalog.Price{80000, 70000, 60000}, finals, "a retried payload is delivered before the next ones")
+}
+
+func TestService_Prime(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewWebhookRepo()
+	products := inmem.NewProductRepo([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)})
+	lister := listing.NewProductLister(products, pricingacl.NewCalculater(inmem.NewDiscountRepo(nil)))
+	previous := webhooks.NewService(repo, lister, webhooks.WithPrivateNetworks())
+	require.NoError(t, previous.Prime(ctx))
+	require.NoError(t, previous.Close())
+
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	_, err := previous.Register(ctx, srv.URL, "")
+	require.NoError(t, err)
+	// the price changes while no service runs.
+	_ = products.Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 79000)})
+
+	service := webhooks.NewService(repo, lister, webhooks.WithPrivateNetworks())
+	defer service.Close()
+	require.NoError(t, service.Prime(ctx))
+	service.Wait()
+
+	require.Len(t, rc.payloads, 1)
+	assert.Equal(t, catalog.Price(89000), rc.payloads[0].Before.Final)
+	assert.Equal(t, catalog.Price(79000), rc.payloads[0].After.Final)
+}
+
+func TestService_Close(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewWebhookRepo()
+	products := inmem.NewProductRepo([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", 89000)})
+	lister := listing.NewProductLister(products, pricingacl.NewCalculater(inmem.NewDiscountRepo(nil)))
+	rc := &receiver{}
+	released := make(chan struct{})
+	var mu sync.Mutex
+	held := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-released:
+			rc.ServeHTTP(w, r)
+		default:
+			// the body is read so the abort of the client is noticed.
+			_, _ = io.Copy(io.Discard, r.Body)
+			mu.Lock()
+			held++
+			mu.Unlock()
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+
+	previous := webhooks.NewService(repo, lister, webhooks.WithPrivateNetworks())
+	require.NoError(t, previous.Prime(ctx))
+	_, err := previous.Register(ctx, srv.URL, "")
+	require.NoError(t, err)
+	for _, p := range []catalog.Price{80000, 70000, 60000} {
+		_ = products.Save([]*catalog.Product{catalog.NewProduct("000001", "BV Lean leather ankle boots", "boots", p)})
+		require.NoError(t, previous.Publish(ctx, catalog.Event{SKU: "000001"}))
+	}
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return held == 1
+	}, time.Second, time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, previous.Close())
+	assert.Less(t, time.Since(start), time.Second, "the post in flight is aborted")
+	assert.Equal(t, 1, held, "the queued payloads are not posted after closing")
+	letters, _ := repo.DeadLetters(ctx)
+	assert.Empty(t, letters)
+	pending, _ := repo.Pending(ctx)
+	assert.Len(t, pending, 3)
+
+	close(released)
+	service := webhooks.NewService(repo, lister, webhooks.WithPrivateNetworks())
+	defer service.Close()
+	require.NoError(t, service.Prime(ctx))
+	service.Wait()
+
+	var finals []catalog.Price
+	for _, p := range rc.payloads {
+		finals = append(finals, p.After.Final)
+	}
+	assert.Equal(t, []catalog.Price{80000, 70000, 60000}, finals, "the pending payloads are delivered on the next start")
+	pending, _ = repo.Pending(ctx)
+	assert.Empty(t, pending)
+}
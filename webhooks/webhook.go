@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/amelendres/go-catalog/catalog"
+)
+
+const PriceChangedEvent = "price.changed"
+
+var ErrSubscriptionNotFound = catalog.NewNotFoundError("webhook subscription not found")
+
+// Subscription is an endpoint notified of every final price change, signing
+// the payloads with its secret.
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Payload is the body posted to the subscriptions.
+type Payload struct {
+	ID         string                  `json:"id"`
+	Type       string                  `json:"type"`
+	OccurredAt time.Time               `json:"occurred_at"`
+	SKU        catalog.SKU             `json:"sku"`
+	Before     catalog.DiscountedPrice `json:"before"`
+	After      catalog.DiscountedPrice `json:"after"`
+}
+
+// DeadLetter is a payload that could not be delivered after every attempt.
+type DeadLetter struct {
+	SubscriptionID string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Payload        Payload   `json:"payload"`
+	Attempts       int       `json:"attempts"`
+	Error          string    `json:"error"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+// Delivery is a payload pending delivery to a subscription.
+type Delivery struct {
+	SubscriptionID string  `json:"subscription_id"`
+	Payload        Payload `json:"payload"`
+}
+
+type Repository interface {
+	Add(ctx context.Context, s Subscription) error
+	// Remove drops the subscription along with its pending deliveries.
+	Remove(ctx context.Context, id string) error
+	List(ctx context.Context) ([]Subscription, error)
+	// AddDeadLetter records d, whose delivery is no longer pending.
+	AddDeadLetter(ctx context.Context, d DeadLetter) error
+	DeadLetters(ctx context.Context) ([]DeadLetter, error)
+	// Prices returns the prices the subscriptions were last notified of.
+	Prices(ctx context.Context) (map[catalog.SKU]catalog.DiscountedPrice, error)
+	// SavePrices records the given prices, keeping the ones of other SKUs,
+	// along with the deliveries of their changes, in one write.
+	SavePrices(ctx context.Context, prices map[catalog.SKU]catalog.DiscountedPrice, pending []Delivery) error
+	// Pending returns the deliveries neither delivered nor dead-lettered, in
+	// the order they were saved.
+	Pending(ctx context.Context) ([]Delivery, error)
+	// Delivered drops a pending delivery, if any.
+	Delivered(ctx context.Context, subscriptionID, payloadID string) error
+}